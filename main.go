@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 
@@ -9,9 +10,18 @@ import (
 
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/gateway"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/pinning"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "approve" {
+		if err := approve(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "approve: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	log := logger.CreateLoggerFromEnv(nil, "blue").With("process", "easymcpgateway")
 
 	cfgPath := "config.json"
@@ -31,3 +41,52 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// approve accepts changed tool definitions recorded in the lockfile.
+//
+//	easymcpgateway approve [-config config.json] [-all] [server__tool ...]
+//
+// With no tool names and no -all it lists the tools awaiting approval. A
+// running gateway notices the lockfile change within a few seconds and
+// registers the approved tools, and its own lockfile updates do not undo
+// the approval.
+func approve(args []string) error {
+	fs := flag.NewFlagSet("approve", flag.ContinueOnError)
+	cfgPath := fs.String("config", "config.json", "path to the gateway config")
+	all := fs.Bool("all", false, "approve every pending tool")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return err
+	}
+
+	pins, err := pinning.Load(cfg.Pinning.LockFile)
+	if err != nil {
+		return err
+	}
+
+	names := fs.Args()
+	if *all {
+		names = pins.Pending()
+	}
+	if len(names) == 0 {
+		for _, name := range pins.Pending() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	if err := pins.Approve(names...); err != nil {
+		return err
+	}
+	if err := pins.Save(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Printf("approved %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// Package audit writes the security audit log: one JSON line per proxied
+// tool call, recording who called what, with which arguments, and what the
+// sanitizer made of it. The log is append-only and rotated by size and age.
+//
+// A nil *Log is valid and discards records, so callers need not check
+// whether auditing is enabled.
+package audit
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/auth"
+)
+
+// Record describes one tool call.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Session is the upstream client session ID, empty for stdio.
+	Session string `json:"session,omitempty"`
+	// Principal is the authenticated client, nil when upstream
+	// authentication is off.
+	Principal *auth.Principal `json:"principal,omitempty"`
+	// Tool is the namespaced tool name the client called.
+	Tool           string `json:"tool"`
+	Server         string `json:"server"`
+	DownstreamTool string `json:"downstreamTool"`
+	// Arguments are the arguments as sent by the client. They are never
+	// logged: Write replaces them with ArgumentsHash.
+	Arguments json.RawMessage `json:"-"`
+	// ArgumentsHash is the hex HMAC-SHA256 of the arguments under the log's
+	// hash key, so calls can be correlated without logging their content.
+	// Keying the hash stops small argument payloads from being recovered by
+	// hashing candidate values.
+	ArgumentsHash string `json:"argumentsHash,omitempty"`
+	// DownstreamLatencyMS is zero when the call never reached the
+	// downstream server.
+	DownstreamLatencyMS float64 `json:"downstreamLatencyMs,omitempty"`
+	Outcome             string  `json:"outcome"`
+	// Verdict is the most severe verdict applied by the sanitizer: "pass",
+	// "modify" or "block". Scans in report mode do not count.
+	Verdict string `json:"verdict"`
+	Scans   []Scan `json:"scans,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Scan is the result of a single scanner run during the call.
+type Scan struct {
+	Scanner string   `json:"scanner"`
+	Verdict string   `json:"verdict"`
+	Threats []string `json:"threats,omitempty"`
+	// Reported is set for scanners in report mode, whose verdict was
+	// recorded but not applied.
+	Reported bool `json:"reported,omitempty"`
+}
+
+// Options configures the log file and its rotation.
+type Options struct {
+	Path string
+	// MaxSize rotates the file once it reaches this many bytes; 0 disables
+	// size rotation.
+	MaxSize int64
+	// MaxAge rotates the file once this long has passed since it was
+	// started, including by earlier processes; 0 disables age rotation.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept; 0 keeps all of them.
+	MaxBackups int
+	// HashKey keys ArgumentsHash. Empty uses a random key, so hashes only
+	// correlate calls made while the log is open.
+	HashKey []byte
+}
+
+// Log is an append-only JSONL audit log. It is safe for concurrent use.
+type Log struct {
+	opts Options
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// Open opens (or creates) the log file at opts.Path for appending.
+func Open(opts Options) (*Log, error) {
+	if len(opts.HashKey) == 0 {
+		opts.HashKey = make([]byte, 32)
+		if _, err := rand.Read(opts.HashKey); err != nil {
+			return nil, fmt.Errorf("generating audit hash key: %w", err)
+		}
+	}
+	l := &Log{opts: opts, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating audit log directory: %w", err)
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write appends r to the log, rotating the file first if it is due.
+func (l *Log) Write(r Record) error {
+	if l == nil {
+		return nil
+	}
+
+	if len(r.Arguments) > 0 {
+		mac := hmac.New(sha256.New, l.opts.HashKey)
+		mac.Write(r.Arguments)
+		r.ArgumentsHash = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log closed")
+	}
+	if l.due(int64(len(line))) {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.opts.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	l.file = f
+	l.size = info.Size()
+	l.opened = l.now()
+	if l.size > 0 {
+		// The file was started by an earlier process; age it from then.
+		l.opened = started(l.opts.Path, info)
+	}
+	return nil
+}
+
+// started returns when the non-empty log file at path was started: the
+// time of its first record, or its modification time if that cannot be
+// read.
+func started(path string, info os.FileInfo) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return info.ModTime()
+	}
+	defer f.Close()
+
+	var first struct {
+		Time time.Time `json:"time"`
+	}
+	if err := json.NewDecoder(f).Decode(&first); err != nil || first.Time.IsZero() {
+		return info.ModTime()
+	}
+	return first.Time
+}
+
+// due reports whether the file must be rotated before writing n more
+// bytes. An empty file is never rotated.
+func (l *Log) due(n int64) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.opts.MaxSize > 0 && l.size+n > l.opts.MaxSize {
+		return true
+	}
+	return l.opts.MaxAge > 0 && l.now().Sub(l.opened) >= l.opts.MaxAge
+}
+
+// rotate renames the current file with a timestamp suffix, opens a fresh
+// one and prunes old backups.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("closing audit log: %w", err)
+	}
+	l.file = nil
+
+	if err := os.Rename(l.opts.Path, l.backupName()); err != nil {
+		// Keep appending to the current file rather than losing records.
+		if openErr := l.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+	return l.prune()
+}
+
+// backupTimeFormat sorts lexically in time order and is safe in file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// backupName returns an unused rotated name for the log, e.g. audit.jsonl
+// becomes audit-2025-01-02T15-04-05.000.jsonl.
+func (l *Log) backupName() string {
+	ext := filepath.Ext(l.opts.Path)
+	base := strings.TrimSuffix(l.opts.Path, ext) + "-" + l.now().UTC().Format(backupTimeFormat)
+	name := base + ext
+	for i := 1; fileExists(name); i++ {
+		name = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+	return name
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// backups returns the rotated log files, oldest first: by timestamp, then
+// by the counter backupName adds when a timestamp is already taken.
+func (l *Log) backups() ([]string, error) {
+	ext := filepath.Ext(l.opts.Path)
+	prefix := strings.TrimSuffix(l.opts.Path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return nil, err
+	}
+
+	// A name is the prefix, a timestamp, an optional ".N" counter and the
+	// extension.
+	order := func(name string) (string, int) {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) <= len(backupTimeFormat) {
+			return stamp, 0
+		}
+		n, _ := strconv.Atoi(strings.TrimPrefix(stamp[len(backupTimeFormat):], "."))
+		return stamp[:len(backupTimeFormat)], n
+	}
+	slices.SortFunc(matches, func(a, b string) int {
+		stampA, nA := order(a)
+		stampB, nB := order(b)
+		return cmp.Or(strings.Compare(stampA, stampB), cmp.Compare(nA, nB))
+	})
+	return matches, nil
+}
+
+func (l *Log) prune() error {
+	if l.opts.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := l.backups()
+	if err != nil {
+		return fmt.Errorf("listing audit log backups: %w", err)
+	}
+	for len(backups) > l.opts.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("removing old audit log: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readRecords(t *testing.T, path string) []Record {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []Record
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var r Record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("invalid line %q: %v", sc.Text(), err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLog_appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+
+	for range 2 {
+		l, err := Open(Options{Path: path})
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		err = l.Write(Record{
+			Tool:    "fs__read",
+			Verdict: "block",
+			Scans:   []Scan{{Scanner: "injection", Verdict: "block", Threats: []string{"prompt injection"}}},
+		})
+		if err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+
+	records := readRecords(t, path)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2 (reopening must append)", len(records))
+	}
+	if records[0].Scans[0].Threats[0] != "prompt injection" {
+		t.Errorf("unexpected record %+v", records[0])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
+
+func TestLog_hashesArgumentsWithKey(t *testing.T) {
+	dir := t.TempDir()
+	args := []byte(`{"pin":"1234"}`)
+
+	hash := func(name string, key []byte) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		l, err := Open(Options{Path: path, HashKey: key})
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if err := l.Write(Record{Tool: "t", Arguments: args}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "1234") {
+			t.Errorf("arguments logged: %s", data)
+		}
+		return readRecords(t, path)[0].ArgumentsHash
+	}
+
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write(args)
+	if got, want := hash("a.jsonl", []byte("key")), hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("hash = %s, want HMAC %s", got, want)
+	}
+	if plain := sha256.Sum256(args); hash("b.jsonl", nil) == hex.EncodeToString(plain[:]) {
+		t.Error("hash without a key is the plain SHA-256")
+	}
+}
+
+func TestLog_rotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+
+	l, err := Open(Options{Path: path, MaxSize: 150, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+
+	// Each record is ~100 bytes, so every write after the first rotates.
+	for range 5 {
+		if err := l.Write(Record{Tool: "fs__read", Server: "fs", Verdict: "pass"}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	backups, err := l.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("got %d backups, want 2: %v", len(backups), backups)
+	}
+	if n := len(readRecords(t, path)); n != 1 {
+		t.Errorf("current file has %d records, want 1", n)
+	}
+}
+
+func TestLog_rotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	l, err := Open(Options{Path: path, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+	l.now = func() time.Time { return now }
+	l.opened = now
+
+	if err := l.Write(Record{Tool: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Minute)
+	if err := l.Write(Record{Tool: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	if err := l.Write(Record{Tool: "c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := filepath.Join(filepath.Dir(path), "audit-2025-01-02T16-34-05.000.jsonl")
+	if n := len(readRecords(t, backup)); n != 2 {
+		t.Errorf("backup has %d records, want 2", n)
+	}
+	if n := len(readRecords(t, path)); n != 1 {
+		t.Errorf("current file has %d records, want 1", n)
+	}
+}
+
+func TestLog_rotatesByAgeAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	start := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	// Each process runs for less than MaxAge.
+	for i := range 3 {
+		l, err := Open(Options{Path: path, MaxAge: time.Hour})
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		now := start.Add(time.Duration(i) * 40 * time.Minute)
+		l.now = func() time.Time { return now }
+		if i == 0 {
+			l.opened = now
+		}
+		if err := l.Write(Record{Time: now, Tool: "a"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := (&Log{opts: Options{Path: path}}).backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1: %v", len(backups), backups)
+	}
+	if n := len(readRecords(t, backups[0])); n != 2 {
+		t.Errorf("backup has %d records, want 2", n)
+	}
+}
+
+func TestLog_backupsOrderCollisions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	names := []string{
+		"audit-2025-01-02T15-04-05.000.2.jsonl",
+		"audit-2025-01-02T15-04-05.000.jsonl",
+		"audit-2025-01-02T15-04-06.000.jsonl",
+		"audit-2025-01-02T15-04-05.000.10.jsonl",
+		"audit-2025-01-02T15-04-05.000.1.jsonl",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := (&Log{opts: Options{Path: path}}).backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"audit-2025-01-02T15-04-05.000.jsonl",
+		"audit-2025-01-02T15-04-05.000.1.jsonl",
+		"audit-2025-01-02T15-04-05.000.2.jsonl",
+		"audit-2025-01-02T15-04-05.000.10.jsonl",
+		"audit-2025-01-02T15-04-06.000.jsonl",
+	}
+	for i := range want {
+		want[i] = filepath.Join(dir, want[i])
+	}
+	if !slices.Equal(backups, want) {
+		t.Errorf("backups = %v, want %v", backups, want)
+	}
+}
+
+func TestNilLog(t *testing.T) {
+	var l *Log
+	if err := l.Write(Record{}); err != nil {
+		t.Errorf("Write: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+)
+
+// apiKeyHeader is the alternative to a bearer token for clients that
+// cannot set the Authorization header.
+const apiKeyHeader = "X-API-Key"
+
+// APIKeys authenticates requests carrying one of a fixed set of keys.
+type APIKeys struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	// hash is the SHA-256 of the key, so that comparisons take the same
+	// time whatever the key's length.
+	hash      [sha256.Size]byte
+	principal Principal
+}
+
+// NewAPIKeys loads the configured keys, reading key files once.
+func NewAPIKeys(cfgs []config.APIKeyConfig) (*APIKeys, error) {
+	a := &APIKeys{}
+	for _, c := range cfgs {
+		key := c.Key
+		if c.KeyFile != "" {
+			data, err := os.ReadFile(c.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading api key %s: %w", c.Name, err)
+			}
+			key = strings.TrimSpace(string(data))
+		}
+		if key == "" {
+			return nil, fmt.Errorf("api key %s is empty", c.Name)
+		}
+		a.keys = append(a.keys, apiKey{
+			hash:      sha256.Sum256([]byte(key)),
+			principal: Principal{Subject: c.Name, Method: MethodAPIKey, Roles: c.Roles},
+		})
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator. A bearer token that is not a
+// known key is left for the next authenticator, as it may be a JWT.
+func (a *APIKeys) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		if p := a.lookup(key); p != nil {
+			return p, nil
+		}
+		return nil, fmt.Errorf("unknown api key")
+	}
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	if p := a.lookup(token); p != nil {
+		return p, nil
+	}
+	return nil, ErrNoCredentials
+}
+
+func (a *APIKeys) lookup(key string) *Principal {
+	h := sha256.Sum256([]byte(key))
+	var found *Principal
+	for i := range a.keys {
+		// Check every key so the time taken does not reveal which matched.
+		if subtle.ConstantTimeCompare(h[:], a.keys[i].hash[:]) == 1 {
+			found = &a.keys[i].principal
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	p := *found
+	return &p
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
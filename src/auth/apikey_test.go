@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+)
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "ci.key")
+	if err := os.WriteFile(keyFile, []byte("key-from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := NewAPIKeys([]config.APIKeyConfig{
+		{Name: "support-bot", Key: "key-support", Roles: []string{"support"}},
+		{Name: "ci", KeyFile: keyFile},
+	})
+	if err != nil {
+		t.Fatalf("NewAPIKeys: %v", err)
+	}
+
+	tests := []struct {
+		name, header, value string
+		wantSubject         string
+		wantErr             error
+	}{
+		{name: "bearer", header: "Authorization", value: "Bearer key-support", wantSubject: "support-bot"},
+		{name: "lowercase scheme", header: "Authorization", value: "bearer key-support", wantSubject: "support-bot"},
+		{name: "api key header", header: "X-API-Key", value: "key-support", wantSubject: "support-bot"},
+		{name: "key file", header: "X-API-Key", value: "key-from-file", wantSubject: "ci"},
+		{name: "no credentials", wantErr: ErrNoCredentials},
+		{name: "basic auth", header: "Authorization", value: "Basic a2V5", wantErr: ErrNoCredentials},
+		// An unknown bearer token may be a JWT for the next authenticator.
+		{name: "unknown bearer", header: "Authorization", value: "Bearer nope", wantErr: ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			p, err := keys.Authenticate(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Subject != tt.wantSubject || p.Method != MethodAPIKey {
+				t.Errorf("principal = %+v, want subject %s", p, tt.wantSubject)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("POST", "/mcp", nil)
+	r.Header.Set("X-API-Key", "key-support")
+	p, _ := keys.Authenticate(r)
+	if !slices.Equal(p.Roles, []string{"support"}) {
+		t.Errorf("roles = %v, want [support]", p.Roles)
+	}
+
+	r.Header.Set("X-API-Key", "nope")
+	if _, err := keys.Authenticate(r); err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Errorf("unknown X-API-Key: err = %v, want rejection", err)
+	}
+}
+
+func TestNewAPIKeys_emptyKeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "empty.key")
+	if err := os.WriteFile(keyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAPIKeys([]config.APIKeyConfig{{Name: "ci", KeyFile: keyFile}}); err == nil {
+		t.Error("expected error for empty key file")
+	}
+}
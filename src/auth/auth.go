@@ -0,0 +1,212 @@
+// Package auth authenticates upstream clients of the HTTP transport and
+// carries the authenticated principal in the request context.
+//
+// The MCP SDK derives the context of every request in a session from the
+// HTTP request that initialized it, so the principal attached by
+// Middleware is available to tool handlers through PrincipalFrom.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+)
+
+// Authentication methods, used as Principal.Method and to qualify access
+// rule subjects. config.AuthMethods lists the same names.
+const (
+	MethodAPIKey = "apikey"
+	MethodJWT    = "jwt"
+	MethodMTLS   = "mtls"
+)
+
+// Principal is an authenticated upstream client.
+type Principal struct {
+	// Subject identifies the client: the key name for API keys, the "sub"
+	// claim for JWTs, the common name for client certificates.
+	Subject string   `json:"subject"`
+	Method  string   `json:"method"`
+	Roles   []string `json:"roles,omitempty"`
+}
+
+// String returns the principal as "method:subject".
+func (p *Principal) String() string {
+	return p.Method + ":" + p.Subject
+}
+
+// ErrNoCredentials is returned by an Authenticator when the request carries
+// no credentials it understands, so the next one should be tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator verifies the credentials of an HTTP request.
+type Authenticator interface {
+	// Authenticate returns the principal making r, ErrNoCredentials, or an
+	// error describing why the credentials were rejected.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// New builds the authenticators configured in cfg: API keys first, then
+// JWT.
+func New(ctx context.Context, cfg config.AuthConfig) ([]Authenticator, error) {
+	var authns []Authenticator
+	if len(cfg.APIKeys) > 0 {
+		keys, err := NewAPIKeys(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		authns = append(authns, keys)
+	}
+	if cfg.JWT != nil {
+		j, err := NewJWT(ctx, *cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		authns = append(authns, j)
+	}
+	return authns, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal in ctx, or nil for unauthenticated
+// transports such as stdio.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// sessionHeader carries the MCP session ID of the streamable HTTP
+// transport.
+const sessionHeader = "Mcp-Session-Id"
+
+// Middleware rejects requests that none of authns authenticate with 401
+// Unauthorized and attaches the principal to the context of the rest.
+//
+// Because a session keeps the principal that initialized it, requests for
+// an existing session must come from that same principal; others are
+// refused with 403 Forbidden.
+func Middleware(authns []Authenticator, logger *slog.Logger) func(http.Handler) http.Handler {
+	owners := &sessionOwners{m: make(map[string]string)}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := authenticate(authns, r)
+			if err != nil {
+				logger.Warn("rejected unauthenticated request", "remote", r.RemoteAddr, "err", err)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			id := r.Header.Get(sessionHeader)
+			if id != "" && !owners.allowed(id, p.String()) {
+				logger.Warn("rejected request for another principal's session", "principal", p, "remote", r.RemoteAddr)
+				http.Error(w, "session belongs to another principal", http.StatusForbidden)
+				return
+			}
+
+			r = r.WithContext(WithPrincipal(r.Context(), p))
+			if id != "" {
+				if r.Method == http.MethodDelete {
+					defer owners.remove(id)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			// Requests without a session may create one; record its owner
+			// from the response header.
+			next.ServeHTTP(&sessionRecorder{ResponseWriter: w, onHeader: func(h http.Header) {
+				if id := h.Get(sessionHeader); id != "" {
+					owners.set(id, p.String())
+				}
+			}}, r)
+		})
+	}
+}
+
+// authenticate returns the principal of the first authenticator that
+// recognises the request's credentials.
+func authenticate(authns []Authenticator, r *http.Request) (*Principal, error) {
+	for _, a := range authns {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// sessionOwners maps session IDs to the principal that created them.
+// Sessions the client never deletes are kept for the life of the gateway,
+// like the upstream sessions themselves.
+type sessionOwners struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+// allowed reports whether principal may use the session. Unknown sessions
+// are allowed through for the SDK to reject.
+func (o *sessionOwners) allowed(id, principal string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	owner, ok := o.m[id]
+	return !ok || owner == principal
+}
+
+func (o *sessionOwners) set(id, principal string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.m[id] = principal
+}
+
+func (o *sessionOwners) remove(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.m, id)
+}
+
+// sessionRecorder calls onHeader with the response headers just before
+// they are written.
+type sessionRecorder struct {
+	http.ResponseWriter
+	onHeader func(http.Header)
+	done     bool
+}
+
+func (w *sessionRecorder) WriteHeader(code int) {
+	w.record()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionRecorder) Write(b []byte) (int, error) {
+	w.record()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush supports the SDK's streamed responses.
+func (w *sessionRecorder) Flush() {
+	w.record()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *sessionRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *sessionRecorder) record() {
+	if !w.done {
+		w.done = true
+		w.onHeader(w.Header())
+	}
+}
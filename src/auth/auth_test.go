@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// whoamiServer serves an MCP server over streamable HTTP behind Middleware.
+// Its "whoami" tool returns the principal in the handler's context.
+func whoamiServer(t *testing.T, authns ...Authenticator) *httptest.Server {
+	t.Helper()
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	srv.AddTool(&mcp.Tool{Name: "whoami", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text := "anonymous"
+		if p := PrincipalFrom(ctx); p != nil {
+			text = p.String()
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+	})
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil)
+	ts := httptest.NewServer(Middleware(authns, testLogger())(handler))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// headerTransport sets a header on every request.
+type headerTransport struct {
+	key, value string
+}
+
+func (h headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set(h.key, h.value)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func connect(t *testing.T, ctx context.Context, url, header, value string) (*mcp.ClientSession, error) {
+	t.Helper()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	return client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   url,
+		HTTPClient: &http.Client{Transport: headerTransport{header, value}},
+	}, nil)
+}
+
+func testAPIKeys(t *testing.T) *APIKeys {
+	t.Helper()
+	keys, err := NewAPIKeys([]config.APIKeyConfig{
+		{Name: "support-bot", Key: "key-support", Roles: []string{"support"}},
+		{Name: "ops", Key: "key-ops"},
+	})
+	if err != nil {
+		t.Fatalf("NewAPIKeys: %v", err)
+	}
+	return keys
+}
+
+func TestMiddleware_attachesPrincipal(t *testing.T) {
+	ctx := context.Background()
+	ts := whoamiServer(t, testAPIKeys(t))
+
+	session, err := connect(t, ctx, ts.URL, "Authorization", "Bearer key-support")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if got := res.Content[0].(*mcp.TextContent).Text; got != "apikey:support-bot" {
+		t.Errorf("whoami = %q, want apikey:support-bot", got)
+	}
+}
+
+func TestMiddleware_rejectsUnauthenticated(t *testing.T) {
+	ts := whoamiServer(t, testAPIKeys(t))
+
+	tests := []struct {
+		name, header, value string
+	}{
+		{name: "no credentials"},
+		{name: "unknown bearer", header: "Authorization", value: "Bearer nope"},
+		{name: "unknown api key", header: "X-API-Key", value: "nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{}`))
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+			if resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestMiddleware_pinsSessionToPrincipal(t *testing.T) {
+	ctx := context.Background()
+	ts := whoamiServer(t, testAPIKeys(t))
+
+	session, err := connect(t, ctx, ts.URL, "X-API-Key", "key-ops")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	post := func(key string) int {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL,
+			strings.NewReader(`{"jsonrpc":"2.0","id":99,"method":"tools/list"}`))
+		req.Header.Set("X-API-Key", key)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set(sessionHeader, session.ID())
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("key-support"); code != http.StatusForbidden {
+		t.Errorf("other principal: status = %d, want %d", code, http.StatusForbidden)
+	}
+	if code := post("key-ops"); code != http.StatusOK {
+		t.Errorf("session owner: status = %d, want %d", code, http.StatusOK)
+	}
+}
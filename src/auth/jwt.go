@@ -0,0 +1,380 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtMethods are the signing algorithms accepted. The key a token names
+// must also be of the matching type, so an RSA key cannot verify an HMAC
+// signature.
+var jwtMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// jwksRefreshInterval limits how often a JWKS URL is refetched for tokens
+// signed with an unknown key.
+const jwksRefreshInterval = time.Minute
+
+// maxJWKSSize bounds the JWKS document read from a file or URL.
+const maxJWKSSize = 1 << 20
+
+// JWT authenticates requests carrying a bearer JWT signed by a key in a
+// JSON Web Key Set.
+type JWT struct {
+	cfg    config.JWTConfig
+	parser *jwt.Parser
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]jwk
+	fetched time.Time
+	// refreshing is the JWKS refetch in progress, if any. Requests that
+	// need the set refreshed wait for it rather than fetching again.
+	refreshing *jwksRefresh
+}
+
+// jwksRefresh is a JWKS refetch shared by the requests waiting for it.
+type jwksRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWT loads the key set configured in cfg.
+func NewJWT(ctx context.Context, cfg config.JWTConfig) (*JWT, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	j := &JWT{
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := j.load(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Authenticate implements Authenticator.
+func (j *JWT) Authenticate(r *http.Request) (*Principal, error) {
+	raw, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := j.parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		return j.key(r.Context(), t)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil || sub == "" {
+		return nil, fmt.Errorf("invalid token: missing subject")
+	}
+	return &Principal{Subject: sub, Method: MethodJWT, Roles: stringsClaim(claims[j.cfg.RolesClaim])}, nil
+}
+
+// key returns the verification key for t, refetching a JWKS URL once the
+// refresh interval has passed if t names a key that is not in the set. The
+// fetch runs without j.mu held, so requests with known keys are not held
+// up by a slow endpoint.
+func (j *JWT) key(ctx context.Context, t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	k, ok, refresh := j.lookupOrRefresh(ctx, kid)
+	if refresh != nil {
+		select {
+		case <-refresh.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if refresh.err != nil {
+			return nil, refresh.err
+		}
+		j.mu.Lock()
+		k, ok = j.lookup(kid)
+		j.mu.Unlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if k.alg != "" && k.alg != t.Method.Alg() {
+		return nil, fmt.Errorf("signing key %q is for %s, not %s", kid, k.alg, t.Method.Alg())
+	}
+	return k.key, nil
+}
+
+// lookupOrRefresh finds the key named kid. If there is none and the JWKS
+// URL is due a refetch, it returns the refresh to wait for, starting one
+// unless another request already has.
+func (j *JWT) lookupOrRefresh(ctx context.Context, kid string) (jwk, bool, *jwksRefresh) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if k, ok := j.lookup(kid); ok || j.cfg.JWKSURL == "" {
+		return k, ok, nil
+	}
+	if j.refreshing != nil {
+		return jwk{}, false, j.refreshing
+	}
+	if time.Since(j.fetched) < jwksRefreshInterval {
+		return jwk{}, false, nil
+	}
+
+	// Record the attempt even if it fails, so that a broken endpoint is
+	// not hit on every request.
+	j.fetched = time.Now()
+	j.refreshing = &jwksRefresh{done: make(chan struct{})}
+	// The fetch outlives a request that gives up waiting for it, since
+	// others may be waiting too; the client timeout bounds it.
+	go j.refresh(context.WithoutCancel(ctx), j.refreshing)
+	return jwk{}, false, j.refreshing
+}
+
+// refresh refetches the key set and swaps it in if it loads.
+func (j *JWT) refresh(ctx context.Context, r *jwksRefresh) {
+	keys, err := j.read(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err == nil {
+		j.keys = keys
+	}
+	r.err = err
+	j.refreshing = nil
+	close(r.done)
+}
+
+// lookup finds the key named kid. Tokens without a kid may use the only
+// key of a single-key set. Must be called with j.mu held.
+func (j *JWT) lookup(kid string) (jwk, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, k := range j.keys {
+			return k, true
+		}
+	}
+	k, ok := j.keys[kid]
+	return k, ok
+}
+
+func (j *JWT) load(ctx context.Context) error {
+	keys, err := j.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = keys
+	j.fetched = time.Now()
+	return nil
+}
+
+// read loads and parses the configured key set.
+func (j *JWT) read(ctx context.Context) (map[string]jwk, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if j.cfg.JWKSURL != "" {
+		data, err = j.fetch(ctx)
+	} else {
+		data, err = os.ReadFile(j.cfg.JWKSFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading jwks: %w", err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("loading jwks: %w", err)
+	}
+	return keys, nil
+}
+
+func (j *JWT) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", j.cfg.JWKSURL, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// jwk is a parsed signing key from a JWKS.
+type jwk struct {
+	key any
+	// alg restricts the key to one algorithm when the JWKS says so.
+	alg string
+}
+
+// parseJWKS returns the signing keys of a JWKS document by key ID. Keys of
+// unsupported types or for encryption are skipped.
+func parseJWKS(data []byte) (map[string]jwk, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing jwks: %w", err)
+	}
+
+	keys := make(map[string]jwk, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key any
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k.N, k.E)
+		case "EC":
+			key, err = ecKey(k.Crv, k.X, k.Y)
+		case "OKP":
+			key, err = okpKey(k.Crv, k.X)
+		default:
+			continue
+		}
+		if errors.Is(err, errUnsupportedCurve) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = jwk{key: key, alg: k.Alg}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks has no usable signing keys")
+	}
+	return keys, nil
+}
+
+var errUnsupportedCurve = errors.New("unsupported curve")
+
+func rsaKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("decoding n: %w", err)
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("decoding e: %w", err)
+	}
+	exp := new(big.Int).SetBytes(eb)
+	if len(nb) == 0 || !exp.IsInt64() || exp.Int64() < 2 || exp.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid rsa key")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(exp.Int64())}, nil
+}
+
+func ecKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, errUnsupportedCurve
+	}
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, fmt.Errorf("decoding x: %w", err)
+	}
+	yb, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil {
+		return nil, fmt.Errorf("decoding y: %w", err)
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(xb) != size || len(yb) != size {
+		return nil, fmt.Errorf("invalid %s coordinates", crv)
+	}
+	point := append(append([]byte{4}, xb...), yb...)
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
+
+func okpKey(crv, x string) (ed25519.PublicKey, error) {
+	if crv != "Ed25519" {
+		return nil, errUnsupportedCurve
+	}
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, fmt.Errorf("decoding x: %w", err)
+	}
+	if len(xb) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 key")
+	}
+	return ed25519.PublicKey(xb), nil
+}
+
+// stringsClaim reads a claim holding either an array of strings or a
+// space-separated string, as OAuth scopes are.
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		var out []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
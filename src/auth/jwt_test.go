@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKey is a signing key and its public JWK.
+type testKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, method: jwt.SigningMethodRS256, key: k}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, method: jwt.SigningMethodES256, key: k}
+}
+
+func (k testKey) jwk() map[string]any {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := k.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]any{"kty": "RSA", "kid": k.kid, "use": "sig", "n": b64(pub.N.Bytes()), "e": b64(big.NewInt(int64(pub.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		raw, _ := pub.Bytes()
+		return map[string]any{"kty": "EC", "kid": k.kid, "crv": "P-256", "x": b64(raw[1:33]), "y": b64(raw[33:])}
+	}
+	panic("unsupported key")
+}
+
+func (k testKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(k.method, claims)
+	tok.Header["kid"] = k.kid
+	s, err := tok.SignedString(k.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func jwks(keys ...testKey) []byte {
+	var set struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	data, _ := json.Marshal(set)
+	return data
+}
+
+func writeJWKS(t *testing.T, keys ...testKey) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks(keys...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func bearerRequest(token string) *http.Request {
+	r := httptest.NewRequest("POST", "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "alice",
+		"iss":   "https://idp.example.com",
+		"aud":   "easy-mcp-gateway",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"support", "oncall"},
+	}
+}
+
+func TestJWT_Authenticate(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t, "rsa"), newECKey(t, "ec")
+	other := newRSAKey(t, "rsa")
+
+	j, err := NewJWT(context.Background(), config.JWTConfig{
+		JWKSFile:   writeJWKS(t, rsaKey, ecKey),
+		Issuer:     "https://idp.example.com",
+		Audience:   "easy-mcp-gateway",
+		RolesClaim: "roles",
+	})
+	if err != nil {
+		t.Fatalf("NewJWT: %v", err)
+	}
+
+	with := func(k string, v any) jwt.MapClaims {
+		c := validClaims()
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "rsa", token: rsaKey.sign(t, validClaims())},
+		{name: "ec", token: ecKey.sign(t, validClaims())},
+		{name: "wrong issuer", token: rsaKey.sign(t, with("iss", "https://evil.example.com")), wantErr: true},
+		{name: "wrong audience", token: rsaKey.sign(t, with("aud", "other")), wantErr: true},
+		{name: "expired", token: rsaKey.sign(t, with("exp", time.Now().Add(-time.Hour).Unix())), wantErr: true},
+		{name: "no expiry", token: rsaKey.sign(t, with("exp", nil)), wantErr: true},
+		{name: "no subject", token: rsaKey.sign(t, with("sub", nil)), wantErr: true},
+		{name: "untrusted key", token: other.sign(t, validClaims()), wantErr: true},
+		{name: "garbage", token: "not-a-jwt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := j.Authenticate(bearerRequest(tt.token))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Subject != "alice" || p.Method != MethodJWT {
+				t.Errorf("principal = %+v", p)
+			}
+			if !slices.Equal(p.Roles, []string{"support", "oncall"}) {
+				t.Errorf("roles = %v", p.Roles)
+			}
+		})
+	}
+
+	if _, err := j.Authenticate(httptest.NewRequest("POST", "/mcp", nil)); err != ErrNoCredentials {
+		t.Errorf("no token: err = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestJWT_rejectsHMAC(t *testing.T) {
+	key := newRSAKey(t, "rsa")
+	j, err := NewJWT(context.Background(), config.JWTConfig{JWKSFile: writeJWKS(t, key)})
+	if err != nil {
+		t.Fatalf("NewJWT: %v", err)
+	}
+
+	// Signing with the public key as an HMAC secret is the classic
+	// algorithm confusion attack.
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	tok.Header["kid"] = "rsa"
+	s, err := tok.SignedString(key.key.Public().(*rsa.PublicKey).N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Authenticate(bearerRequest(s)); err == nil {
+		t.Error("expected HS256 token to be rejected")
+	}
+}
+
+func TestJWT_refreshesURLForUnknownKey(t *testing.T) {
+	first, second := newRSAKey(t, "first"), newRSAKey(t, "second")
+
+	var current atomic.Value
+	current.Store(jwks(first))
+	var fetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		w.Write(current.Load().([]byte))
+	}))
+	defer ts.Close()
+
+	j, err := NewJWT(context.Background(), config.JWTConfig{JWKSURL: ts.URL, RolesClaim: "roles"})
+	if err != nil {
+		t.Fatalf("NewJWT: %v", err)
+	}
+	if _, err := j.Authenticate(bearerRequest(first.sign(t, validClaims()))); err != nil {
+		t.Fatalf("first key: %v", err)
+	}
+
+	// The key is rotated; within the refresh interval the set is not
+	// refetched.
+	current.Store(jwks(second))
+	if _, err := j.Authenticate(bearerRequest(second.sign(t, validClaims()))); err == nil {
+		t.Fatal("expected unknown key to be rejected within the refresh interval")
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+
+	j.mu.Lock()
+	j.fetched = time.Now().Add(-jwksRefreshInterval)
+	j.mu.Unlock()
+	if _, err := j.Authenticate(bearerRequest(second.sign(t, validClaims()))); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+}
+
+func TestJWT_refreshDoesNotHoldUpKnownKeys(t *testing.T) {
+	first, second := newRSAKey(t, "first"), newRSAKey(t, "second")
+
+	var fetches atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fetches.Add(1) == 1 {
+			w.Write(jwks(first))
+			return
+		}
+		started <- struct{}{}
+		<-release
+		w.Write(jwks(first, second))
+	}))
+	defer ts.Close()
+
+	j, err := NewJWT(context.Background(), config.JWTConfig{JWKSURL: ts.URL})
+	if err != nil {
+		t.Fatalf("NewJWT: %v", err)
+	}
+	j.mu.Lock()
+	j.fetched = time.Now().Add(-jwksRefreshInterval)
+	j.mu.Unlock()
+
+	// Several requests with the rotated key share one refetch.
+	const waiting = 5
+	rotated, known := second.sign(t, validClaims()), first.sign(t, validClaims())
+	errs := make(chan error, waiting)
+	for range waiting {
+		go func() {
+			_, err := j.Authenticate(bearerRequest(rotated))
+			errs <- err
+		}()
+	}
+	<-started
+
+	// While the refetch is stalled, tokens signed with a known key still
+	// authenticate.
+	done := make(chan error, 1)
+	go func() {
+		_, err := j.Authenticate(bearerRequest(known))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("known key: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("known key held up by the JWKS refetch")
+	}
+
+	close(release)
+	for range waiting {
+		if err := <-errs; err != nil {
+			t.Errorf("rotated key: %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name    string
+		jwks    string
+		want    int
+		wantErr bool
+	}{
+		{name: "skips encryption keys", jwks: `{"keys": [{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"}, {"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}]}`, want: 1},
+		{name: "skips unsupported types", jwks: `{"keys": [{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}, {"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}]}`, want: 1},
+		{name: "no usable keys", jwks: `{"keys": [{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}]}`, wantErr: true},
+		{name: "invalid point", jwks: `{"keys": [{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", "y": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}]}`, wantErr: true},
+		{name: "malformed", jwks: `{"keys": `, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseJWKS([]byte(tt.jwks))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(keys) != tt.want {
+				t.Errorf("got %d keys, want %d", len(keys), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ClientCert authenticates requests by the client certificate verified
+// during the TLS handshake. It relies on the listener verifying
+// certificates against the configured CA bundle, and so only trusts
+// verified chains.
+type ClientCert struct{}
+
+// Authenticate implements Authenticator. The principal is the leaf
+// certificate subject's common name, or the whole subject when it has
+// none, and its organizational units are the roles.
+func (ClientCert) Authenticate(r *http.Request) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return nil, ErrNoCredentials
+	}
+	subject := r.TLS.VerifiedChains[0][0].Subject
+	name := subject.CommonName
+	if name == "" {
+		name = subject.String()
+	}
+	if name == "" {
+		return nil, fmt.Errorf("client certificate has an empty subject")
+	}
+	return &Principal{Subject: name, Method: MethodMTLS, Roles: subject.OrganizationalUnit}, nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestClientCert(t *testing.T) {
+	withCert := func(subject pkix.Name, verified bool) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: subject}
+		state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		if verified {
+			state.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+		return state
+	}
+
+	t.Run("verified", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/mcp", nil)
+		r.TLS = withCert(pkix.Name{CommonName: "billing-agent", OrganizationalUnit: []string{"support", "billing"}}, true)
+		p, err := ClientCert{}.Authenticate(r)
+		if err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		if p.String() != "mtls:billing-agent" || !slices.Equal(p.Roles, []string{"support", "billing"}) {
+			t.Errorf("principal = %+v", p)
+		}
+	})
+
+	t.Run("no common name", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/mcp", nil)
+		r.TLS = withCert(pkix.Name{Organization: []string{"Example"}}, true)
+		p, err := ClientCert{}.Authenticate(r)
+		if err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		if p.Subject != "O=Example" {
+			t.Errorf("subject = %q, want O=Example", p.Subject)
+		}
+	})
+
+	for name, state := range map[string]*tls.ConnectionState{
+		"plain HTTP":   nil,
+		"no cert":      {},
+		"not verified": withCert(pkix.Name{CommonName: "mallory"}, false),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			r.TLS = state
+			if _, err := (ClientCert{}).Authenticate(r); err != ErrNoCredentials {
+				t.Errorf("err = %v, want ErrNoCredentials", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+)
+
+// Policy decides which tools a principal may list and call. It governs a
+// server's prompts, resources and resource templates the same way, matching
+// their downstream names where it matches tool names.
+//
+// A nil *Policy allows everything, so callers need not check whether
+// access rules are configured.
+type Policy struct {
+	rules []config.AccessRule
+}
+
+// NewPolicy returns the policy for rules, or nil when there are none.
+func NewPolicy(rules []config.AccessRule) *Policy {
+	if len(rules) == 0 {
+		return nil
+	}
+	return &Policy{rules: rules}
+}
+
+// Allows reports whether p may use tool on the downstream server. tool may
+// also name a prompt, resource or resource template of the server. Without
+// a principal, as on the stdio upstream, nothing is allowed.
+func (pol *Policy) Allows(p *Principal, server, tool string) bool {
+	if pol == nil {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	for _, rule := range pol.rules {
+		if matchesPrincipal(rule, p) && matchesTool(rule.Tools, server, tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPrincipal reports whether rule names p by a role it holds or by
+// its method-qualified subject, as returned by Principal.String.
+func matchesPrincipal(rule config.AccessRule, p *Principal) bool {
+	for _, s := range rule.Subjects {
+		if s == "*" || s == p.String() {
+			return true
+		}
+	}
+	for _, role := range rule.Roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTool reports whether any "server/tool" pattern matches. Patterns
+// have been validated by config.Load.
+func matchesTool(patterns []string, server, tool string) bool {
+	for _, pattern := range patterns {
+		sp, tp, _ := strings.Cut(pattern, "/")
+		if ok, _ := path.Match(sp, server); !ok {
+			continue
+		}
+		if ok, _ := path.Match(tp, tool); ok {
+			return true
+		}
+	}
+	return false
+}
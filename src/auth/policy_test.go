@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+)
+
+func TestPolicy_Allows(t *testing.T) {
+	pol := NewPolicy([]config.AccessRule{
+		{Roles: []string{"support"}, Tools: []string{"docs/*", "tickets/read_*"}},
+		{Subjects: []string{"jwt:ops"}, Tools: []string{"*/*"}},
+		{Subjects: []string{"*"}, Tools: []string{"status/ping"}},
+	})
+
+	support := &Principal{Subject: "support-bot", Method: MethodAPIKey, Roles: []string{"support"}}
+	ops := &Principal{Subject: "ops", Method: MethodJWT}
+	other := &Principal{Subject: "someone", Method: MethodJWT, Roles: []string{"sales"}}
+	opsKey := &Principal{Subject: "ops", Method: MethodAPIKey}
+
+	tests := []struct {
+		name         string
+		principal    *Principal
+		server, tool string
+		want         bool
+	}{
+		{name: "role grants server", principal: support, server: "docs", tool: "search", want: true},
+		{name: "role grants tool pattern", principal: support, server: "tickets", tool: "read_ticket", want: true},
+		{name: "role does not grant tool", principal: support, server: "tickets", tool: "close_ticket"},
+		{name: "role does not grant server", principal: support, server: "prod-db", tool: "query"},
+		{name: "subject grants everything", principal: ops, server: "prod-db", tool: "query", want: true},
+		{name: "subject of another method", principal: opsKey, server: "prod-db", tool: "query"},
+		{name: "wildcard subject", principal: other, server: "status", tool: "ping", want: true},
+		{name: "no matching rule", principal: other, server: "docs", tool: "search"},
+		{name: "no principal", server: "status", tool: "ping"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pol.Allows(tt.principal, tt.server, tt.tool); got != tt.want {
+				t.Errorf("Allows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_nilAllowsEverything(t *testing.T) {
+	pol := NewPolicy(nil)
+	if pol != nil {
+		t.Fatal("expected nil policy without rules")
+	}
+	if !pol.Allows(nil, "prod-db", "query") {
+		t.Error("nil policy should allow everything")
+	}
+}
@@ -1,22 +1,48 @@
 package config
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 )
 
+// ScannerNames lists the sanitizer scanners that can be configured by name.
+var ScannerNames = []string{"unicode", "secrets", "pii", "length", "injection", "override", "url", "boundary"}
+
+// AuthMethods lists the upstream authentication methods that qualify
+// access rule subjects. They match auth.Principal.Method.
+var AuthMethods = []string{"apikey", "jwt", "mtls"}
+
 // validName matches alphanumeric, hyphens, and single underscores.
 // Double underscores are reserved as the namespace separator.
 var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
 
+// validToolName matches the characters MCP recommends for tool names.
+var validToolName = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,128}$`)
+
+// validSeparator matches namespace separators: punctuation only, so that a
+// separator cannot be confused with part of a server or tool name.
+var validSeparator = regexp.MustCompile(`^[_.-]{1,4}$`)
+
 // Config is the top-level gateway configuration loaded from JSON.
 type Config struct {
 	Upstream     UpstreamConfig     `json:"upstream"`
 	Downstream   []DownstreamConfig `json:"downstream"`
 	Sanitization SanitizationConfig `json:"sanitization"`
+	Pinning      PinningConfig      `json:"pinning"`
+	Metrics      MetricsConfig      `json:"metrics"`
+	Tracing      TracingConfig      `json:"tracing"`
+	Audit        AuditConfig        `json:"audit"`
+	Admin        AdminConfig        `json:"admin"`
+	Access       AccessConfig       `json:"access"`
 }
 
 // UpstreamConfig controls how LLM clients connect to the gateway.
@@ -29,6 +55,63 @@ type UpstreamConfig struct {
 type HTTPConfig struct {
 	Addr string `json:"addr"` // e.g. ":8080"
 	Path string `json:"path"` // e.g. "/mcp"
+	// MinConnected is the number of downstream servers that must be
+	// connected for /readyz to report ready, in addition to every server
+	// marked required. Defaults to 1.
+	MinConnected *int `json:"minConnected,omitempty"`
+	// Auth requires clients of the MCP endpoint to authenticate. The probes
+	// and any metrics served on this listener stay open.
+	Auth *AuthConfig `json:"auth,omitempty"`
+	// TLS serves the HTTP transport, probes included, over HTTPS.
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+// AuthConfig lists the credentials accepted from upstream clients. A
+// request is authenticated by the first method its credentials match.
+type AuthConfig struct {
+	APIKeys []APIKeyConfig `json:"apiKeys,omitempty"`
+	JWT     *JWTConfig     `json:"jwt,omitempty"`
+}
+
+// APIKeyConfig is a static key, sent as "Authorization: Bearer <key>" or
+// in the X-API-Key header. Exactly one of Key and KeyFile is set.
+type APIKeyConfig struct {
+	// Name identifies the client as the principal's subject.
+	Name    string   `json:"name"`
+	Key     string   `json:"key,omitempty"`
+	KeyFile string   `json:"keyFile,omitempty"` // relative paths resolve against the config file's directory
+	Roles   []string `json:"roles,omitempty"`
+}
+
+// JWTConfig validates bearer JWTs against a JSON Web Key Set. Exactly one
+// of JWKSFile and JWKSURL is set.
+type JWTConfig struct {
+	JWKSFile string `json:"jwksFile,omitempty"` // relative paths resolve against the config file's directory
+	// JWKSURL is fetched at startup and again, at most once a minute, when
+	// a token is signed with an unknown key.
+	JWKSURL string `json:"jwksURL,omitempty"`
+	// Issuer and Audience, when set, must match the "iss" and "aud"
+	// claims.
+	Issuer   string `json:"issuer,omitempty"`
+	Audience string `json:"audience,omitempty"`
+	// RolesClaim names the claim holding the principal's roles, as an
+	// array or a space-separated string. Defaults to "roles".
+	RolesClaim string `json:"rolesClaim,omitempty"`
+}
+
+// TLSConfig is the certificate of the HTTP upstream. The files are
+// re-read when they change, so certificates can be rotated without
+// restarting the gateway or dropping established sessions. Relative paths
+// resolve against the config file's directory.
+type TLSConfig struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+	// ClientCAFile is a PEM bundle of CAs trusted to issue client
+	// certificates. A verified client certificate authenticates the MCP
+	// endpoint as a principal named by the certificate subject's common
+	// name, with its organizational units as roles. Certificates are
+	// optional at the TLS layer so that the probes stay reachable.
+	ClientCAFile string `json:"clientCAFile,omitempty"`
 }
 
 // DownstreamConfig defines a single downstream MCP server.
@@ -38,12 +121,255 @@ type DownstreamConfig struct {
 	Command      []string            `json:"command,omitempty"`
 	URL          string              `json:"url,omitempty"`
 	Sanitization *SanitizationConfig `json:"sanitization,omitempty"`
+
+	// Prefix replaces the server name as the namespace prefix of its tools
+	// upstream. An empty string exposes the tools without a prefix; tools
+	// whose name collides with one already registered, by a server listed
+	// earlier or one that connected first, are then refused. Like server
+	// names, a prefix may not contain "__" or the server's separator.
+	Prefix *string `json:"prefix,omitempty"`
+	// Separator joins the prefix and tool name. Defaults to "__".
+	Separator string `json:"separator,omitempty"`
+
+	// SkipArgumentValidation turns off checking tool call arguments against
+	// the tool's input schema at the gateway, for servers whose schemas are
+	// unreliable.
+	SkipArgumentValidation bool `json:"skipArgumentValidation,omitempty"`
+
+	// OutputValidation controls checking structured tool results against
+	// the tool's output schema: "off", "warn" or "block". Properties the
+	// schema does not declare count as violations unless the schema sets
+	// additionalProperties, patternProperties or unevaluatedProperties
+	// itself.
+	OutputValidation string `json:"outputValidation,omitempty"`
+
+	// Include and Exclude are tool name glob patterns (path.Match syntax)
+	// that curate which of the server's tools are exposed upstream. An empty
+	// Include exposes every tool; Exclude always wins.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+
+	// AnnotationFilter hides tools based on their MCP annotations.
+	AnnotationFilter *AnnotationFilter `json:"annotationFilter,omitempty"`
+
+	// Required marks the gateway unready on the HTTP upstream's /readyz
+	// while this server is not connected.
+	Required bool `json:"required,omitempty"`
+
+	// Tools holds per-tool settings keyed by tool name or glob pattern
+	// (path.Match syntax, e.g. "read_*"). Every matching entry applies,
+	// least specific first, so an exact name overrides a pattern.
+	Tools map[string]ToolConfig `json:"tools,omitempty"`
+}
+
+// AnnotationFilter hides tools by their MCP annotation hints. Hints a tool
+// does not declare take their defaults from the MCP spec: a tool is assumed
+// destructive and open-world, and not read-only, unless it says otherwise.
+type AnnotationFilter struct {
+	ExcludeDestructive bool `json:"excludeDestructive,omitempty"` // hide tools that may perform destructive updates
+	RequireReadOnly    bool `json:"requireReadOnly,omitempty"`    // hide tools without readOnlyHint
+	ExcludeOpenWorld   bool `json:"excludeOpenWorld,omitempty"`   // hide tools that interact with external entities
+}
+
+// ToolConfig holds settings for the tools matched by one tools entry.
+type ToolConfig struct {
+	// Name renames the tool upstream; the prefix and separator still apply.
+	// Only valid on exact tool names, not glob patterns.
+	Name string `json:"name,omitempty"`
+	// Description replaces the downstream description; DescriptionAppend
+	// is added after it. Both are taken from config as-is and bypass
+	// sanitization.
+	Description       string `json:"description,omitempty"`
+	DescriptionAppend string `json:"descriptionAppend,omitempty"`
+
+	// FixedArguments are always sent to the downstream tool, overriding
+	// anything the LLM supplied, and are removed from the advertised input
+	// schema. DefaultArguments fill in arguments the LLM left out and are
+	// advertised as schema defaults.
+	FixedArguments   map[string]any `json:"fixedArguments,omitempty"`
+	DefaultArguments map[string]any `json:"defaultArguments,omitempty"`
+
+	// Sanitization is merged on top of the server-level config.
+	Sanitization *SanitizationConfig `json:"sanitization,omitempty"`
+}
+
+// ResolveTool combines every tools entry matching toolName into one
+// ToolConfig, applying them from least to most specific: later non-empty
+// fields win, argument maps are merged key by key and sanitization
+// overrides are merged in order.
+func (d DownstreamConfig) ResolveTool(toolName string) ToolConfig {
+	var out ToolConfig
+	for _, tc := range d.MatchTools(toolName) {
+		if tc.Name != "" {
+			out.Name = tc.Name
+		}
+		if tc.Description != "" {
+			out.Description = tc.Description
+		}
+		if tc.DescriptionAppend != "" {
+			out.DescriptionAppend = tc.DescriptionAppend
+		}
+		out.FixedArguments = mergeArgumentValues(out.FixedArguments, tc.FixedArguments)
+		out.DefaultArguments = mergeArgumentValues(out.DefaultArguments, tc.DefaultArguments)
+		if tc.Sanitization != nil {
+			if out.Sanitization == nil {
+				out.Sanitization = tc.Sanitization
+			} else {
+				merged := Merge(out.Sanitization, tc.Sanitization)
+				out.Sanitization = &merged
+			}
+		}
+	}
+	return out
+}
+
+// MatchTools returns the tools entries whose key matches toolName, ordered
+// from least to most specific: glob patterns before exact names, and
+// shorter patterns before longer ones.
+func (d DownstreamConfig) MatchTools(toolName string) []ToolConfig {
+	var keys []string
+	for pattern := range d.Tools {
+		if ok, _ := path.Match(pattern, toolName); ok {
+			keys = append(keys, pattern)
+		}
+	}
+
+	exact := func(pattern string) int {
+		if pattern == toolName {
+			return 1
+		}
+		return 0
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(exact(a), exact(b)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(literalLen(a), literalLen(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	matched := make([]ToolConfig, 0, len(keys))
+	for _, k := range keys {
+		matched = append(matched, d.Tools[k])
+	}
+	return matched
+}
+
+// mergeArgumentValues returns base with override's keys set on top, without
+// modifying either map.
+func mergeArgumentValues(base, override map[string]any) map[string]any {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]any, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+	return merged
+}
+
+// literalLen counts the characters of a glob pattern that are not
+// wildcards, as a rough measure of how specific it is.
+func literalLen(pattern string) int {
+	return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+}
+
+// PinningConfig controls tool definition pinning. Each downstream tool
+// definition is fingerprinted and the hash stored in a lockfile; a changed
+// definition is handled according to Policy until approved with
+// "easymcpgateway approve".
+type PinningConfig struct {
+	Policy   string `json:"policy"`   // "off", "warn" or "block"
+	LockFile string `json:"lockFile"` // relative paths resolve against the config file's directory
+}
+
+// MetricsConfig controls the Prometheus metrics endpoint.
+type MetricsConfig struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	// Addr serves metrics on a dedicated listener, e.g. ":9090". When empty
+	// they are served on the upstream HTTP listener, which requires the http
+	// upstream transport.
+	Addr string `json:"addr,omitempty"`
+	Path string `json:"path,omitempty"` // e.g. "/metrics"
+}
+
+// TracingConfig controls OpenTelemetry tracing of proxied tool calls.
+type TracingConfig struct {
+	Enabled  *bool  `json:"enabled,omitempty"`
+	Exporter string `json:"exporter,omitempty"` // "otlp" or "stdout"
+	// Endpoint is the OTLP/HTTP collector, e.g. "localhost:4318". When
+	// empty the standard OTEL_EXPORTER_OTLP_* environment variables apply.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Insecure sends OTLP over plain HTTP instead of HTTPS.
+	Insecure bool `json:"insecure,omitempty"`
+	// SampleRatio is the fraction of new traces sampled, from 0 to 1.
+	// Calls that arrive with a sampled trace context are always traced.
+	SampleRatio *float64 `json:"sampleRatio,omitempty"`
+}
+
+// AuditConfig controls the security audit log, a JSONL file with one
+// record per tool call.
+type AuditConfig struct {
+	Enabled *bool  `json:"enabled,omitempty"`
+	Path    string `json:"path,omitempty"` // relative paths resolve against the config file's directory
+	// MaxSizeMB rotates the log once it reaches this size; 0 disables size
+	// rotation.
+	MaxSizeMB *int `json:"maxSizeMB,omitempty"`
+	// MaxAge rotates the log once this long has passed since it was
+	// started, as a Go duration such as "24h"; empty disables age rotation.
+	MaxAge string `json:"maxAge,omitempty"`
+	// MaxBackups is the number of rotated logs kept; 0 keeps all of them.
+	MaxBackups *int `json:"maxBackups,omitempty"`
+	// HashKey keys the hash of call arguments recorded in the log. Empty
+	// uses a random per-process key, so hashes only correlate calls made
+	// by the same run of the gateway.
+	HashKey string `json:"hashKey,omitempty"`
+}
+
+// AccessConfig restricts the tools authenticated upstream clients may list
+// and call. Without rules every client may use every tool; with rules a
+// client may only use the tools granted by a rule that matches it.
+type AccessConfig struct {
+	Rules []AccessRule `json:"rules,omitempty"`
+}
+
+// AccessRule grants tools to the principals with any of Roles or Subjects.
+// Subjects are qualified by authentication method, e.g. "apikey:support-bot",
+// "jwt:alice" or "mtls:gateway-client", so that a JWT subject cannot match
+// a rule meant for an API key of the same name. The subject "*" matches
+// every authenticated principal.
+type AccessRule struct {
+	Roles    []string `json:"roles,omitempty"`
+	Subjects []string `json:"subjects,omitempty"`
+	// Tools are "server/tool" patterns over downstream server and tool
+	// names, each part in path.Match syntax, e.g. "github/*" or
+	// "*/read_*". They grant the server's prompts, resources and resource
+	// templates by name in the same way.
+	Tools []string `json:"tools"`
+}
+
+// AdminConfig controls the admin API, a read-only set of JSON endpoints
+// describing downstream servers, registered tools and call statistics.
+type AdminConfig struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	// Addr is the admin listener, e.g. "localhost:9091". It is never the
+	// upstream listener; metrics configured with the same addr share it.
+	Addr string `json:"addr,omitempty"`
 }
 
 // SanitizationConfig controls the sanitization pipeline behaviour.
 // When used at the root level it provides global defaults.
 // When used per-downstream server, non-nil fields override the global.
 type SanitizationConfig struct {
+	// Mode is "enforce" or "report". In report mode the scanners still run
+	// and their findings are logged, audited and counted, but content is
+	// passed through unchanged.
+	Mode string `json:"mode,omitempty"`
+	// ScannerModes sets the mode of individual scanners by name, e.g.
+	// {"injection": "report"}, taking precedence over Mode.
+	ScannerModes map[string]string `json:"scannerModes,omitempty"`
+
 	MaxResponseChars               *int     `json:"maxResponseChars,omitempty"`
 	EnablePromptInjectionDetection *bool    `json:"enablePromptInjectionDetection,omitempty"`
 	EnableInvisibleTextRemoval     *bool    `json:"enableInvisibleTextRemoval,omitempty"`
@@ -52,15 +378,99 @@ type SanitizationConfig struct {
 	EnableSystemOverrideDetection  *bool    `json:"enableSystemOverrideDetection,omitempty"`
 	DisableBuiltInPatterns         *bool    `json:"disableBuiltInPatterns,omitempty"`
 	CustomInjectionPatterns        []string `json:"customInjectionPatterns,omitempty"`
+
+	// Secret redaction replaces credentials in responses with typed
+	// placeholders such as [REDACTED:aws_access_key]. Off by default, as it
+	// rewrites tool output.
+	EnableSecretRedaction     *bool        `json:"enableSecretRedaction,omitempty"`
+	DisableBuiltInSecretRules *bool        `json:"disableBuiltInSecretRules,omitempty"`
+	CustomSecretRules         []SecretRule `json:"customSecretRules,omitempty"`
+
+	// PII configures detection of personal data in responses.
+	PII *PIIConfig `json:"pii,omitempty"`
+
+	// Arguments configures the outbound pipeline that scans tool call
+	// arguments before they are forwarded downstream.
+	Arguments *ArgumentSanitizationConfig `json:"arguments,omitempty"`
+}
+
+// SecretRule is a user-defined credential pattern. If Pattern has a capture
+// group named "secret" only that group is redacted. MinEntropy, in bits per
+// character, suppresses low-entropy matches such as placeholder values.
+type SecretRule struct {
+	Name       string  `json:"name"`
+	Pattern    string  `json:"pattern"`
+	MinEntropy float64 `json:"minEntropy,omitempty"`
+}
+
+// PIIConfig controls detection of personal data in responses. Each entity
+// type can be toggled individually; Action decides whether matches are
+// redacted, replaced with a stable keyed hash, or cause the response to be
+// blocked. Non-nil fields override the global value.
+type PIIConfig struct {
+	Enabled    *bool  `json:"enabled,omitempty"`
+	Action     string `json:"action,omitempty"`  // "redact", "hash" or "block"
+	HashKey    string `json:"hashKey,omitempty"` // empty uses a random per-process key
+	Email      *bool  `json:"email,omitempty"`
+	Phone      *bool  `json:"phone,omitempty"`
+	IBAN       *bool  `json:"iban,omitempty"`
+	CreditCard *bool  `json:"creditCard,omitempty"`
+	NationalID *bool  `json:"nationalId,omitempty"`
+}
+
+// ArgumentSanitizationConfig controls scanning of the string values in tool
+// call arguments produced by the LLM. A blocked call is answered with an
+// error result and never reaches the downstream server. Injection and secret
+// detection share their built-in and custom pattern settings with the
+// response pipeline. Scanning is opt-in: it is off unless enabled is set,
+// and then runs every scanner not turned off. Non-nil fields override the
+// global value.
+type ArgumentSanitizationConfig struct {
+	Enabled                        *bool `json:"enabled,omitempty"`
+	EnablePromptInjectionDetection *bool `json:"enablePromptInjectionDetection,omitempty"`
+	EnableSystemOverrideDetection  *bool `json:"enableSystemOverrideDetection,omitempty"`
+	EnableURLValidation            *bool `json:"enableURLValidation,omitempty"`
+	EnableSecretDetection          *bool `json:"enableSecretDetection,omitempty"`
 }
 
 const (
 	TransportStdio = "stdio"
 	TransportHTTP  = "http"
 
+	PinningOff   = "off"
+	PinningWarn  = "warn"
+	PinningBlock = "block"
+
+	OutputValidationOff   = "off"
+	OutputValidationWarn  = "warn"
+	OutputValidationBlock = "block"
+
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout" // writes to stderr; stdout carries the stdio transport
+
+	ModeEnforce = "enforce"
+	ModeReport  = "report"
+
+	PIIActionRedact = "redact"
+	PIIActionHash   = "hash"
+	PIIActionBlock  = "block"
+
 	DefaultMaxResponseChars = 16000
 	DefaultHTTPAddr         = ":8080"
 	DefaultHTTPPath         = "/mcp"
+	DefaultLockFile         = "tools.lock.json"
+	DefaultSeparator        = "__"
+	DefaultMetricsPath      = "/metrics"
+	DefaultAuditPath        = "audit.jsonl"
+	DefaultAuditMaxSizeMB   = 100
+	DefaultAuditMaxBackups  = 10
+	DefaultAdminAddr        = "localhost:9091"
+	DefaultRolesClaim       = "roles"
+
+	// HealthzPath and ReadyzPath are the liveness and readiness probes
+	// served on the HTTP upstream.
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
 )
 
 // Load reads and parses a JSON config file, applies defaults, and validates.
@@ -77,6 +487,26 @@ func Load(path string) (Config, error) {
 
 	applyDefaults(&cfg)
 
+	if !filepath.IsAbs(cfg.Pinning.LockFile) {
+		cfg.Pinning.LockFile = filepath.Join(filepath.Dir(path), cfg.Pinning.LockFile)
+	}
+	if !filepath.IsAbs(cfg.Audit.Path) {
+		cfg.Audit.Path = filepath.Join(filepath.Dir(path), cfg.Audit.Path)
+	}
+	if a := cfg.Upstream.HTTP.Auth; a != nil {
+		for i := range a.APIKeys {
+			a.APIKeys[i].KeyFile = resolvePath(path, a.APIKeys[i].KeyFile)
+		}
+		if a.JWT != nil {
+			a.JWT.JWKSFile = resolvePath(path, a.JWT.JWKSFile)
+		}
+	}
+	if t := cfg.Upstream.HTTP.TLS; t != nil {
+		t.CertFile = resolvePath(path, t.CertFile)
+		t.KeyFile = resolvePath(path, t.KeyFile)
+		t.ClientCAFile = resolvePath(path, t.ClientCAFile)
+	}
+
 	if err := validate(cfg); err != nil {
 		return Config{}, fmt.Errorf("validating config: %w", err)
 	}
@@ -84,6 +514,15 @@ func Load(path string) (Config, error) {
 	return cfg, nil
 }
 
+// resolvePath resolves a relative, non-empty path against the directory of
+// the config file.
+func resolvePath(configPath, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(configPath), p)
+}
+
 func applyDefaults(cfg *Config) {
 	if cfg.Upstream.Transport == "" {
 		cfg.Upstream.Transport = TransportStdio
@@ -94,7 +533,70 @@ func applyDefaults(cfg *Config) {
 	if cfg.Upstream.HTTP.Path == "" {
 		cfg.Upstream.HTTP.Path = DefaultHTTPPath
 	}
+	if cfg.Upstream.HTTP.MinConnected == nil {
+		cfg.Upstream.HTTP.MinConnected = intPtr(1)
+	}
+	if a := cfg.Upstream.HTTP.Auth; a != nil && a.JWT != nil && a.JWT.RolesClaim == "" {
+		a.JWT.RolesClaim = DefaultRolesClaim
+	}
 
+	for i := range cfg.Downstream {
+		if cfg.Downstream[i].Separator == "" {
+			cfg.Downstream[i].Separator = DefaultSeparator
+		}
+		if cfg.Downstream[i].OutputValidation == "" {
+			cfg.Downstream[i].OutputValidation = OutputValidationWarn
+		}
+	}
+
+	if cfg.Pinning.Policy == "" {
+		cfg.Pinning.Policy = PinningWarn
+	}
+	if cfg.Pinning.LockFile == "" {
+		cfg.Pinning.LockFile = DefaultLockFile
+	}
+
+	if cfg.Metrics.Enabled == nil {
+		cfg.Metrics.Enabled = boolPtr(false)
+	}
+	if cfg.Metrics.Path == "" {
+		cfg.Metrics.Path = DefaultMetricsPath
+	}
+
+	if cfg.Tracing.Enabled == nil {
+		cfg.Tracing.Enabled = boolPtr(false)
+	}
+	if cfg.Tracing.Exporter == "" {
+		cfg.Tracing.Exporter = TracingExporterOTLP
+	}
+	if cfg.Tracing.SampleRatio == nil {
+		ratio := 1.0
+		cfg.Tracing.SampleRatio = &ratio
+	}
+
+	if cfg.Admin.Enabled == nil {
+		cfg.Admin.Enabled = boolPtr(false)
+	}
+	if cfg.Admin.Addr == "" {
+		cfg.Admin.Addr = DefaultAdminAddr
+	}
+
+	if cfg.Audit.Enabled == nil {
+		cfg.Audit.Enabled = boolPtr(false)
+	}
+	if cfg.Audit.Path == "" {
+		cfg.Audit.Path = DefaultAuditPath
+	}
+	if cfg.Audit.MaxSizeMB == nil {
+		cfg.Audit.MaxSizeMB = intPtr(DefaultAuditMaxSizeMB)
+	}
+	if cfg.Audit.MaxBackups == nil {
+		cfg.Audit.MaxBackups = intPtr(DefaultAuditMaxBackups)
+	}
+
+	if cfg.Sanitization.Mode == "" {
+		cfg.Sanitization.Mode = ModeEnforce
+	}
 	if cfg.Sanitization.MaxResponseChars == nil {
 		cfg.Sanitization.MaxResponseChars = intPtr(DefaultMaxResponseChars)
 	}
@@ -116,6 +618,48 @@ func applyDefaults(cfg *Config) {
 	if cfg.Sanitization.DisableBuiltInPatterns == nil {
 		cfg.Sanitization.DisableBuiltInPatterns = boolPtr(false)
 	}
+	if cfg.Sanitization.EnableSecretRedaction == nil {
+		cfg.Sanitization.EnableSecretRedaction = boolPtr(false)
+	}
+	if cfg.Sanitization.DisableBuiltInSecretRules == nil {
+		cfg.Sanitization.DisableBuiltInSecretRules = boolPtr(false)
+	}
+
+	if cfg.Sanitization.PII == nil {
+		cfg.Sanitization.PII = &PIIConfig{}
+	}
+	pii := cfg.Sanitization.PII
+	if pii.Enabled == nil {
+		pii.Enabled = boolPtr(false)
+	}
+	if pii.Action == "" {
+		pii.Action = PIIActionRedact
+	}
+	for _, entity := range []**bool{&pii.Email, &pii.Phone, &pii.IBAN, &pii.CreditCard, &pii.NationalID} {
+		if *entity == nil {
+			*entity = boolPtr(true)
+		}
+	}
+
+	if cfg.Sanitization.Arguments == nil {
+		cfg.Sanitization.Arguments = &ArgumentSanitizationConfig{}
+	}
+	args := cfg.Sanitization.Arguments
+	if args.Enabled == nil {
+		args.Enabled = boolPtr(false)
+	}
+	if args.EnablePromptInjectionDetection == nil {
+		args.EnablePromptInjectionDetection = boolPtr(true)
+	}
+	if args.EnableSystemOverrideDetection == nil {
+		args.EnableSystemOverrideDetection = boolPtr(true)
+	}
+	if args.EnableURLValidation == nil {
+		args.EnableURLValidation = boolPtr(true)
+	}
+	if args.EnableSecretDetection == nil {
+		args.EnableSecretDetection = boolPtr(true)
+	}
 }
 
 func validate(cfg Config) error {
@@ -123,10 +667,54 @@ func validate(cfg Config) error {
 		return fmt.Errorf("upstream transport must be %q or %q, got %q",
 			TransportStdio, TransportHTTP, cfg.Upstream.Transport)
 	}
+	if p := cfg.Upstream.HTTP.Path; p == HealthzPath || p == ReadyzPath {
+		return fmt.Errorf("upstream http path %q is reserved for health probes", p)
+	}
+	if err := validateAuth(cfg.Upstream); err != nil {
+		return err
+	}
+	if err := validateTLS(cfg.Upstream); err != nil {
+		return err
+	}
+
+	switch cfg.Pinning.Policy {
+	case PinningOff, PinningWarn, PinningBlock:
+	default:
+		return fmt.Errorf("pinning policy must be %q, %q or %q, got %q",
+			PinningOff, PinningWarn, PinningBlock, cfg.Pinning.Policy)
+	}
+
+	if err := validateMetrics(cfg.Metrics, cfg.Upstream); err != nil {
+		return err
+	}
+	if err := validateAdmin(cfg.Admin, cfg.Metrics, cfg.Upstream); err != nil {
+		return err
+	}
+	if err := validateAccess(cfg.Access, cfg.Upstream); err != nil {
+		return err
+	}
+
+	switch cfg.Tracing.Exporter {
+	case TracingExporterOTLP, TracingExporterStdout:
+	default:
+		return fmt.Errorf("tracing exporter must be %q or %q, got %q",
+			TracingExporterOTLP, TracingExporterStdout, cfg.Tracing.Exporter)
+	}
+	if r := *cfg.Tracing.SampleRatio; r < 0 || r > 1 {
+		return fmt.Errorf("tracing sampleRatio must be between 0 and 1, got %v", r)
+	}
+
+	if err := validateAudit(cfg.Audit); err != nil {
+		return err
+	}
 
 	if len(cfg.Downstream) == 0 {
 		return fmt.Errorf("at least one downstream server is required")
 	}
+	if n := *cfg.Upstream.HTTP.MinConnected; n < 0 || n > len(cfg.Downstream) {
+		return fmt.Errorf("upstream http minConnected must be between 0 and the number of downstream servers (%d), got %d",
+			len(cfg.Downstream), n)
+	}
 
 	names := make(map[string]struct{}, len(cfg.Downstream))
 	for i, ds := range cfg.Downstream {
@@ -165,21 +753,288 @@ func validate(cfg Config) error {
 		}
 	}
 
+	if err := validateSecretRules(cfg.Sanitization.CustomSecretRules); err != nil {
+		return fmt.Errorf("sanitization.%w", err)
+	}
+	if err := validatePII(cfg.Sanitization.PII); err != nil {
+		return fmt.Errorf("sanitization.%w", err)
+	}
+	if err := validateModes(&cfg.Sanitization); err != nil {
+		return fmt.Errorf("sanitization.%w", err)
+	}
+
 	for di, ds := range cfg.Downstream {
-		if ds.Sanitization == nil {
-			continue
+		if err := validateSanitization(ds.Sanitization); err != nil {
+			return fmt.Errorf("downstream[%d] (%s) sanitization.%w", di, ds.Name, err)
+		}
+
+		for _, pattern := range slices.Concat(ds.Include, ds.Exclude) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("downstream[%d] (%s): invalid tool pattern %q: %w", di, ds.Name, pattern, err)
+			}
 		}
-		for i, pattern := range ds.Sanitization.CustomInjectionPatterns {
-			if _, err := regexp.Compile(pattern); err != nil {
-				return fmt.Errorf("downstream[%d] (%s) sanitization.customInjectionPatterns[%d]: invalid regex %q: %w",
-					di, ds.Name, i, pattern, err)
+
+		if ds.Prefix != nil && *ds.Prefix != "" {
+			if !validName.MatchString(*ds.Prefix) {
+				return fmt.Errorf("downstream[%d] (%s): prefix %q must match %s", di, ds.Name, *ds.Prefix, validName.String())
+			}
+			for _, sep := range []string{DefaultSeparator, ds.Separator} {
+				if sep != "" && strings.Contains(*ds.Prefix, sep) {
+					return fmt.Errorf("downstream[%d] (%s): prefix %q must not contain the separator %q", di, ds.Name, *ds.Prefix, sep)
+				}
 			}
 		}
+		switch ds.OutputValidation {
+		case OutputValidationOff, OutputValidationWarn, OutputValidationBlock:
+		default:
+			return fmt.Errorf("downstream[%d] (%s): outputValidation must be %q, %q or %q, got %q",
+				di, ds.Name, OutputValidationOff, OutputValidationWarn, OutputValidationBlock, ds.OutputValidation)
+		}
+		if !validSeparator.MatchString(ds.Separator) {
+			return fmt.Errorf("downstream[%d] (%s): separator %q must match %s", di, ds.Name, ds.Separator, validSeparator.String())
+		}
+
+		renamed := make(map[string]string)
+		for pattern, tc := range ds.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("downstream[%d] (%s) tools: invalid pattern %q: %w", di, ds.Name, pattern, err)
+			}
+			if err := validateSanitization(tc.Sanitization); err != nil {
+				return fmt.Errorf("downstream[%d] (%s) tools[%q].sanitization.%w", di, ds.Name, pattern, err)
+			}
+			if tc.Name == "" {
+				continue
+			}
+			if strings.ContainsAny(pattern, `*?[\`) {
+				return fmt.Errorf("downstream[%d] (%s) tools[%q]: name can only be set for an exact tool name", di, ds.Name, pattern)
+			}
+			if !validToolName.MatchString(tc.Name) {
+				return fmt.Errorf("downstream[%d] (%s) tools[%q]: name %q must match %s", di, ds.Name, pattern, tc.Name, validToolName.String())
+			}
+			if other, exists := renamed[tc.Name]; exists {
+				return fmt.Errorf("downstream[%d] (%s): tools %q and %q are both renamed to %q", di, ds.Name, other, pattern, tc.Name)
+			}
+			renamed[tc.Name] = pattern
+		}
+	}
+
+	return nil
+}
+
+// validateMetrics checks the metrics path, and that metrics have a listener
+// of their own unless they can share the HTTP upstream's without clashing
+// with its routes.
+func validateMetrics(m MetricsConfig, upstream UpstreamConfig) error {
+	if m.Enabled == nil || !*m.Enabled {
+		return nil
+	}
+	if !strings.HasPrefix(m.Path, "/") {
+		return fmt.Errorf("metrics.path %q must start with \"/\"", m.Path)
+	}
+	if m.Addr != "" {
+		return nil
+	}
+	if upstream.Transport != TransportHTTP {
+		return fmt.Errorf("metrics.addr is required unless the upstream transport is %q", TransportHTTP)
+	}
+	if m.Path == upstream.HTTP.Path || m.Path == HealthzPath || m.Path == ReadyzPath {
+		return fmt.Errorf("metrics.path %q conflicts with the upstream http path", m.Path)
+	}
+	return nil
+}
+
+func validateAuth(upstream UpstreamConfig) error {
+	a := upstream.HTTP.Auth
+	if a == nil {
+		return nil
+	}
+	if upstream.Transport != TransportHTTP {
+		return fmt.Errorf("upstream http auth requires the %q upstream transport", TransportHTTP)
+	}
+	if len(a.APIKeys) == 0 && a.JWT == nil {
+		return fmt.Errorf("upstream http auth must configure apiKeys or jwt")
+	}
+
+	names := make(map[string]struct{}, len(a.APIKeys))
+	for i, k := range a.APIKeys {
+		if k.Name == "" {
+			return fmt.Errorf("upstream http auth apiKeys[%d]: name is required", i)
+		}
+		if _, exists := names[k.Name]; exists {
+			return fmt.Errorf("upstream http auth apiKeys[%d]: duplicate name %q", i, k.Name)
+		}
+		names[k.Name] = struct{}{}
+		if (k.Key == "") == (k.KeyFile == "") {
+			return fmt.Errorf("upstream http auth apiKeys[%d] (%s): exactly one of key and keyFile is required", i, k.Name)
+		}
+	}
+
+	if j := a.JWT; j != nil && (j.JWKSFile == "") == (j.JWKSURL == "") {
+		return fmt.Errorf("upstream http auth jwt: exactly one of jwksFile and jwksURL is required")
+	}
+	return nil
+}
+
+func validateTLS(upstream UpstreamConfig) error {
+	t := upstream.HTTP.TLS
+	if t == nil {
+		return nil
+	}
+	if upstream.Transport != TransportHTTP {
+		return fmt.Errorf("upstream http tls requires the %q upstream transport", TransportHTTP)
+	}
+	if t.CertFile == "" || t.KeyFile == "" {
+		return fmt.Errorf("upstream http tls: certFile and keyFile are required")
+	}
+	return nil
+}
+
+func validateAccess(a AccessConfig, upstream UpstreamConfig) error {
+	if len(a.Rules) == 0 {
+		return nil
+	}
+	if upstream.HTTP.Auth == nil && (upstream.HTTP.TLS == nil || upstream.HTTP.TLS.ClientCAFile == "") {
+		return fmt.Errorf("access rules require upstream http auth or tls.clientCAFile")
+	}
+	for i, rule := range a.Rules {
+		if len(rule.Roles) == 0 && len(rule.Subjects) == 0 {
+			return fmt.Errorf("access.rules[%d]: roles or subjects are required", i)
+		}
+		for _, subject := range rule.Subjects {
+			if subject == "*" {
+				continue
+			}
+			method, name, ok := strings.Cut(subject, ":")
+			if !ok || name == "" || !slices.Contains(AuthMethods, method) {
+				return fmt.Errorf("access.rules[%d]: subject %q must be \"*\" or \"method:subject\" with method one of %s",
+					i, subject, strings.Join(AuthMethods, ", "))
+			}
+		}
+		if len(rule.Tools) == 0 {
+			return fmt.Errorf("access.rules[%d]: tools are required", i)
+		}
+		for _, pattern := range rule.Tools {
+			server, tool, ok := strings.Cut(pattern, "/")
+			if !ok {
+				return fmt.Errorf("access.rules[%d]: tool pattern %q must be \"server/tool\"", i, pattern)
+			}
+			for _, p := range []string{server, tool} {
+				if _, err := path.Match(p, ""); err != nil {
+					return fmt.Errorf("access.rules[%d]: invalid tool pattern %q: %w", i, pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateAdmin(a AdminConfig, m MetricsConfig, upstream UpstreamConfig) error {
+	if a.Enabled == nil || !*a.Enabled {
+		return nil
+	}
+	if upstream.Transport == TransportHTTP && a.Addr == upstream.HTTP.Addr {
+		return fmt.Errorf("admin.addr %q must differ from the upstream http addr", a.Addr)
+	}
+	if m.Enabled != nil && *m.Enabled && m.Addr == a.Addr && strings.HasPrefix(m.Path, "/admin/") {
+		return fmt.Errorf("metrics.path %q conflicts with the admin API", m.Path)
+	}
+	return nil
+}
+
+func validateAudit(a AuditConfig) error {
+	if *a.MaxSizeMB < 0 {
+		return fmt.Errorf("audit.maxSizeMB must not be negative, got %d", *a.MaxSizeMB)
+	}
+	if *a.MaxBackups < 0 {
+		return fmt.Errorf("audit.maxBackups must not be negative, got %d", *a.MaxBackups)
+	}
+	if a.MaxAge != "" {
+		d, err := time.ParseDuration(a.MaxAge)
+		if err != nil {
+			return fmt.Errorf("audit.maxAge: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("audit.maxAge must not be negative, got %s", a.MaxAge)
+		}
 	}
+	return nil
+}
 
+// validateSanitization checks the regexes and enums of a per-server or
+// per-tool sanitization override. A nil override is valid.
+func validateSanitization(sc *SanitizationConfig) error {
+	if sc == nil {
+		return nil
+	}
+	for i, pattern := range sc.CustomInjectionPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("customInjectionPatterns[%d]: invalid regex %q: %w", i, pattern, err)
+		}
+	}
+	if err := validateSecretRules(sc.CustomSecretRules); err != nil {
+		return err
+	}
+	if err := validateModes(sc); err != nil {
+		return err
+	}
+	return validatePII(sc.PII)
+}
+
+func validateModes(sc *SanitizationConfig) error {
+	if sc.Mode != "" && sc.Mode != ModeEnforce && sc.Mode != ModeReport {
+		return fmt.Errorf("mode must be %q or %q, got %q", ModeEnforce, ModeReport, sc.Mode)
+	}
+	for _, name := range slices.Sorted(maps.Keys(sc.ScannerModes)) {
+		if !slices.Contains(ScannerNames, name) {
+			return fmt.Errorf("scannerModes: unknown scanner %q, must be one of %s", name, strings.Join(ScannerNames, ", "))
+		}
+		if mode := sc.ScannerModes[name]; mode != ModeEnforce && mode != ModeReport {
+			return fmt.Errorf("scannerModes.%s must be %q or %q, got %q", name, ModeEnforce, ModeReport, mode)
+		}
+	}
 	return nil
 }
 
+func validateSecretRules(rules []SecretRule) error {
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("customSecretRules[%d]: name is required", i)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("customSecretRules[%d] (%s): invalid regex %q: %w", i, rule.Name, rule.Pattern, err)
+		}
+		if rule.MinEntropy < 0 {
+			return fmt.Errorf("customSecretRules[%d] (%s): minEntropy must not be negative", i, rule.Name)
+		}
+	}
+	return nil
+}
+
+func validatePII(pii *PIIConfig) error {
+	if pii == nil {
+		return nil
+	}
+	switch pii.Action {
+	case "", PIIActionRedact, PIIActionHash, PIIActionBlock:
+		return nil
+	default:
+		return fmt.Errorf("pii.action must be %q, %q or %q, got %q",
+			PIIActionRedact, PIIActionHash, PIIActionBlock, pii.Action)
+	}
+}
+
+// ScannerMode returns the effective mode of the named scanner: its own
+// entry in ScannerModes, else Mode, else enforce.
+func (sc SanitizationConfig) ScannerMode(name string) string {
+	if mode := sc.ScannerModes[name]; mode != "" {
+		return mode
+	}
+	if sc.Mode != "" {
+		return sc.Mode
+	}
+	return ModeEnforce
+}
+
 // Merge returns a SanitizationConfig with per-server overrides applied on
 // top of global defaults. Fields that are nil in the override use the global value.
 func Merge(global, override *SanitizationConfig) SanitizationConfig {
@@ -189,6 +1044,16 @@ func Merge(global, override *SanitizationConfig) SanitizationConfig {
 
 	merged := *global
 
+	if override.Mode != "" {
+		merged.Mode = override.Mode
+	}
+	if len(override.ScannerModes) > 0 {
+		merged.ScannerModes = maps.Clone(global.ScannerModes)
+		if merged.ScannerModes == nil {
+			merged.ScannerModes = make(map[string]string, len(override.ScannerModes))
+		}
+		maps.Copy(merged.ScannerModes, override.ScannerModes)
+	}
 	if override.MaxResponseChars != nil {
 		merged.MaxResponseChars = override.MaxResponseChars
 	}
@@ -213,9 +1078,87 @@ func Merge(global, override *SanitizationConfig) SanitizationConfig {
 	if len(override.CustomInjectionPatterns) > 0 {
 		merged.CustomInjectionPatterns = override.CustomInjectionPatterns
 	}
+	if override.EnableSecretRedaction != nil {
+		merged.EnableSecretRedaction = override.EnableSecretRedaction
+	}
+	if override.DisableBuiltInSecretRules != nil {
+		merged.DisableBuiltInSecretRules = override.DisableBuiltInSecretRules
+	}
+	if len(override.CustomSecretRules) > 0 {
+		merged.CustomSecretRules = override.CustomSecretRules
+	}
+	merged.PII = mergePII(global.PII, override.PII)
+	merged.Arguments = mergeArguments(global.Arguments, override.Arguments)
 
 	return merged
 }
 
+func mergePII(global, override *PIIConfig) *PIIConfig {
+	if override == nil {
+		return global
+	}
+	if global == nil {
+		return override
+	}
+
+	merged := *global
+
+	if override.Enabled != nil {
+		merged.Enabled = override.Enabled
+	}
+	if override.Action != "" {
+		merged.Action = override.Action
+	}
+	if override.HashKey != "" {
+		merged.HashKey = override.HashKey
+	}
+	if override.Email != nil {
+		merged.Email = override.Email
+	}
+	if override.Phone != nil {
+		merged.Phone = override.Phone
+	}
+	if override.IBAN != nil {
+		merged.IBAN = override.IBAN
+	}
+	if override.CreditCard != nil {
+		merged.CreditCard = override.CreditCard
+	}
+	if override.NationalID != nil {
+		merged.NationalID = override.NationalID
+	}
+
+	return &merged
+}
+
+func mergeArguments(global, override *ArgumentSanitizationConfig) *ArgumentSanitizationConfig {
+	if override == nil {
+		return global
+	}
+	if global == nil {
+		return override
+	}
+
+	merged := *global
+
+	if override.Enabled != nil {
+		merged.Enabled = override.Enabled
+	}
+	if override.EnablePromptInjectionDetection != nil {
+		merged.EnablePromptInjectionDetection = override.EnablePromptInjectionDetection
+	}
+	if override.EnableSystemOverrideDetection != nil {
+		merged.EnableSystemOverrideDetection = override.EnableSystemOverrideDetection
+	}
+	if override.EnableURLValidation != nil {
+		merged.EnableURLValidation = override.EnableURLValidation
+	}
+	if override.EnableSecretDetection != nil {
+		merged.EnableSecretDetection = override.EnableSecretDetection
+	}
+
+	return &merged
+}
+
 func boolPtr(b bool) *bool { return &b }
 func intPtr(i int) *int    { return &i }
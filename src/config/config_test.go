@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -74,6 +75,35 @@ func TestLoad_DefaultsApplied(t *testing.T) {
 	if *got.Sanitization.DisableBuiltInPatterns {
 		t.Error("default disableBuiltInPatterns should be false")
 	}
+	if *got.Sanitization.EnableSecretRedaction {
+		t.Error("default enableSecretRedaction should be false")
+	}
+	if *got.Sanitization.DisableBuiltInSecretRules {
+		t.Error("default disableBuiltInSecretRules should be false")
+	}
+	pii := got.Sanitization.PII
+	if pii == nil {
+		t.Fatal("default pii config should be set")
+	}
+	if *pii.Enabled {
+		t.Error("default pii.enabled should be false")
+	}
+	if pii.Action != PIIActionRedact {
+		t.Errorf("default pii.action = %q, want %q", pii.Action, PIIActionRedact)
+	}
+	if !*pii.Email || !*pii.Phone || !*pii.IBAN || !*pii.CreditCard || !*pii.NationalID {
+		t.Error("default pii entities should all be enabled")
+	}
+	args := got.Sanitization.Arguments
+	if args == nil {
+		t.Fatal("default arguments config should be set")
+	}
+	if *args.Enabled {
+		t.Error("default arguments.enabled should be false")
+	}
+	if !*args.EnablePromptInjectionDetection || !*args.EnableSystemOverrideDetection || !*args.EnableURLValidation || !*args.EnableSecretDetection {
+		t.Error("default argument scanners should all be enabled once scanning is")
+	}
 }
 
 func TestLoad_HTTPUpstream(t *testing.T) {
@@ -177,6 +207,209 @@ func TestLoad_InvalidRegex(t *testing.T) {
 	}
 }
 
+func TestLoad_InvalidSecretRule(t *testing.T) {
+	tests := map[string]string{
+		"bad regex":    `{"name": "x", "pattern": "[invalid"}`,
+		"missing name": `{"pattern": "x"}`,
+	}
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [
+					{"name": "a", "transport": "stdio", "command": ["x"],
+					 "sanitization": {"customSecretRules": [` + rule + `]}}
+				]
+			}`
+			path := writeTemp(t, cfg)
+			if _, err := Load(path); err == nil {
+				t.Fatal("expected error for invalid secret rule")
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidPIIAction(t *testing.T) {
+	cfg := `{
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"]}
+		],
+		"sanitization": {
+			"pii": {"enabled": true, "action": "shred"}
+		}
+	}`
+	path := writeTemp(t, cfg)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for invalid pii action")
+	}
+}
+
+func TestLoad_InvalidToolPattern(t *testing.T) {
+	cfg := `{
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"],
+			 "tools": {"read_[": {}}}
+		]
+	}`
+	path := writeTemp(t, cfg)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for invalid tool pattern")
+	}
+}
+
+func TestLoad_InvalidIncludePattern(t *testing.T) {
+	cfg := `{
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"],
+			 "include": ["read_*"], "exclude": ["[bad"]}
+		]
+	}`
+	path := writeTemp(t, cfg)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for invalid exclude pattern")
+	}
+}
+
+func TestLoad_InvalidToolSanitization(t *testing.T) {
+	cfg := `{
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"],
+			 "tools": {"search": {"sanitization": {"customInjectionPatterns": ["[invalid"]}}}}
+		]
+	}`
+	path := writeTemp(t, cfg)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for invalid regex in tool override")
+	}
+}
+
+func TestLoad_InvalidDownstreamOptions(t *testing.T) {
+	tests := map[string]string{
+		"bad prefix":       `"prefix": "gh hub"`,
+		"prefix with __":   `"prefix": "a__b"`,
+		"prefix with sep":  `"prefix": "gh-hub", "separator": "-"`,
+		"bad separator":    `"separator": "::"`,
+		"bad output mode":  `"outputValidation": "strict"`,
+		"rename on glob":   `"tools": {"get_*": {"name": "get"}}`,
+		"bad rename":       `"tools": {"get_repo": {"name": "get repo"}}`,
+		"duplicate rename": `"tools": {"get_repo": {"name": "repo"}, "fetch_repo": {"name": "repo"}}`,
+	}
+	for name, field := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [
+					{"name": "a", "transport": "stdio", "command": ["x"], ` + field + `}
+				]
+			}`
+			path := writeTemp(t, cfg)
+			if _, err := Load(path); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestLoad_NamingDefaults(t *testing.T) {
+	cfg := `{
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"], "prefix": ""}
+		]
+	}`
+	path := writeTemp(t, cfg)
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ds := got.Downstream[0]
+	if ds.Separator != DefaultSeparator {
+		t.Errorf("separator = %q, want %q", ds.Separator, DefaultSeparator)
+	}
+	if ds.Prefix == nil || *ds.Prefix != "" {
+		t.Error("empty prefix should be preserved")
+	}
+	if ds.OutputValidation != OutputValidationWarn {
+		t.Errorf("outputValidation = %q, want %q", ds.OutputValidation, OutputValidationWarn)
+	}
+}
+
+func TestResolveTool(t *testing.T) {
+	ds := DownstreamConfig{
+		Tools: map[string]ToolConfig{
+			"*": {
+				DescriptionAppend: "generic",
+				Sanitization:      &SanitizationConfig{MaxResponseChars: intPtr(10), EnableURLValidation: boolPtr(true)},
+			},
+			"read_file": {
+				Name:         "read",
+				Sanitization: &SanitizationConfig{MaxResponseChars: intPtr(1000)},
+			},
+		},
+	}
+
+	got := ds.ResolveTool("read_file")
+	if got.Name != "read" {
+		t.Errorf("name = %q, want read", got.Name)
+	}
+	if got.DescriptionAppend != "generic" {
+		t.Errorf("descriptionAppend = %q, want generic", got.DescriptionAppend)
+	}
+	if *got.Sanitization.MaxResponseChars != 1000 {
+		t.Errorf("maxResponseChars = %d, want 1000", *got.Sanitization.MaxResponseChars)
+	}
+	if got.Sanitization.EnableURLValidation == nil || !*got.Sanitization.EnableURLValidation {
+		t.Error("enableURLValidation should be inherited from the glob entry")
+	}
+
+	if other := ds.ResolveTool("write_file"); other.Name != "" || *other.Sanitization.MaxResponseChars != 10 {
+		t.Errorf("write_file should only get the catch-all entry, got %+v", other)
+	}
+}
+
+func TestResolveTool_MergesArguments(t *testing.T) {
+	ds := DownstreamConfig{
+		Tools: map[string]ToolConfig{
+			"*":      {FixedArguments: map[string]any{"org": "our-org", "limit": 10}},
+			"search": {FixedArguments: map[string]any{"limit": 50}, DefaultArguments: map[string]any{"state": "open"}},
+		},
+	}
+
+	got := ds.ResolveTool("search")
+	if got.FixedArguments["org"] != "our-org" || got.FixedArguments["limit"] != 50 {
+		t.Errorf("fixedArguments = %v, want org from * and limit from search", got.FixedArguments)
+	}
+	if got.DefaultArguments["state"] != "open" {
+		t.Errorf("defaultArguments = %v", got.DefaultArguments)
+	}
+	if ds.Tools["*"].FixedArguments["limit"] != 10 {
+		t.Error("tools entry was mutated")
+	}
+}
+
+func TestMatchTools_Order(t *testing.T) {
+	ds := DownstreamConfig{
+		Tools: map[string]ToolConfig{
+			"*":         {Sanitization: &SanitizationConfig{MaxResponseChars: intPtr(1)}},
+			"read_file": {Sanitization: &SanitizationConfig{MaxResponseChars: intPtr(4)}},
+			"read_*":    {Sanitization: &SanitizationConfig{MaxResponseChars: intPtr(2)}},
+			"read_fil?": {Sanitization: &SanitizationConfig{MaxResponseChars: intPtr(3)}},
+			"write_*":   {Sanitization: &SanitizationConfig{MaxResponseChars: intPtr(99)}},
+		},
+	}
+
+	matched := ds.MatchTools("read_file")
+	var got []int
+	for _, tc := range matched {
+		got = append(got, *tc.Sanitization.MaxResponseChars)
+	}
+	want := []int{1, 2, 3, 4}
+	if !slices.Equal(got, want) {
+		t.Errorf("match order = %v, want %v", got, want)
+	}
+
+	if len(ds.MatchTools("delete")) != 1 {
+		t.Error("only the catch-all pattern should match delete")
+	}
+}
+
 func TestLoad_FileNotFound(t *testing.T) {
 	_, err := Load("/nonexistent/config.json")
 	if err == nil {
@@ -231,6 +464,460 @@ func TestLoad_NameWithHyphensAndUnderscores(t *testing.T) {
 	}
 }
 
+func TestLoad_PinningDefaults(t *testing.T) {
+	cfg := `{
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"]}
+		]
+	}`
+
+	path := writeTemp(t, cfg)
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Pinning.Policy != PinningWarn {
+		t.Errorf("default pinning policy = %q, want %q", got.Pinning.Policy, PinningWarn)
+	}
+	want := filepath.Join(filepath.Dir(path), DefaultLockFile)
+	if got.Pinning.LockFile != want {
+		t.Errorf("default lockFile = %q, want %q", got.Pinning.LockFile, want)
+	}
+}
+
+func TestLoad_PinningLockFileRelativeToConfig(t *testing.T) {
+	cfg := `{
+		"pinning": {"policy": "block", "lockFile": "locks/gw.lock"},
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"]}
+		]
+	}`
+
+	path := writeTemp(t, cfg)
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := filepath.Join(filepath.Dir(path), "locks", "gw.lock")
+	if got.Pinning.LockFile != want {
+		t.Errorf("lockFile = %q, want %q", got.Pinning.LockFile, want)
+	}
+}
+
+func TestLoad_InvalidPinningPolicy(t *testing.T) {
+	cfg := `{
+		"pinning": {"policy": "maybe"},
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"]}
+		]
+	}`
+	path := writeTemp(t, cfg)
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for invalid pinning policy")
+	}
+}
+
+func TestLoad_MetricsDefaults(t *testing.T) {
+	cfg := `{
+		"downstream": [
+			{"name": "a", "transport": "stdio", "command": ["x"]}
+		]
+	}`
+
+	got, err := Load(writeTemp(t, cfg))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *got.Metrics.Enabled {
+		t.Error("metrics should be disabled by default")
+	}
+	if got.Metrics.Path != DefaultMetricsPath {
+		t.Errorf("default metrics path = %q, want %q", got.Metrics.Path, DefaultMetricsPath)
+	}
+}
+
+func TestLoad_Metrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{
+			name: "dedicated listener",
+			cfg:  `"metrics": {"enabled": true, "addr": ":9090"}`,
+		},
+		{
+			name: "upstream listener",
+			cfg:  `"upstream": {"transport": "http"}, "metrics": {"enabled": true}`,
+		},
+		{
+			name:    "stdio upstream without addr",
+			cfg:     `"metrics": {"enabled": true}`,
+			wantErr: true,
+		},
+		{
+			name:    "conflicting path",
+			cfg:     `"upstream": {"transport": "http"}, "metrics": {"enabled": true, "path": "/mcp"}`,
+			wantErr: true,
+		},
+		{
+			name:    "relative path",
+			cfg:     `"metrics": {"enabled": true, "addr": ":9090", "path": "metrics"}`,
+			wantErr: true,
+		},
+		{
+			name:    "probe path",
+			cfg:     `"upstream": {"transport": "http"}, "metrics": {"enabled": true, "path": "/readyz"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}],
+				` + tt.cfg + `
+			}`
+			_, err := Load(writeTemp(t, cfg))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Tracing(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{name: "defaults", cfg: `"tracing": {}`},
+		{name: "stdout", cfg: `"tracing": {"enabled": true, "exporter": "stdout", "sampleRatio": 0.25}`},
+		{name: "unknown exporter", cfg: `"tracing": {"exporter": "zipkin"}`, wantErr: true},
+		{name: "ratio out of range", cfg: `"tracing": {"sampleRatio": 1.5}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}],
+				` + tt.cfg + `
+			}`
+			got, err := Load(writeTemp(t, cfg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil || tt.name != "defaults" {
+				return
+			}
+			if *got.Tracing.Enabled || got.Tracing.Exporter != TracingExporterOTLP || *got.Tracing.SampleRatio != 1 {
+				t.Errorf("unexpected defaults %+v", got.Tracing)
+			}
+		})
+	}
+}
+
+func TestLoad_Audit(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{name: "defaults", cfg: `"audit": {}`},
+		{name: "rotation", cfg: `"audit": {"enabled": true, "maxSizeMB": 0, "maxAge": "24h", "maxBackups": 3}`},
+		{name: "invalid max age", cfg: `"audit": {"maxAge": "daily"}`, wantErr: true},
+		{name: "negative size", cfg: `"audit": {"maxSizeMB": -1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}],
+				` + tt.cfg + `
+			}`
+			path := writeTemp(t, cfg)
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil || tt.name != "defaults" {
+				return
+			}
+			if *got.Audit.Enabled {
+				t.Error("audit log should be disabled by default")
+			}
+			if want := filepath.Join(filepath.Dir(path), DefaultAuditPath); got.Audit.Path != want {
+				t.Errorf("audit path = %q, want %q", got.Audit.Path, want)
+			}
+			if *got.Audit.MaxSizeMB != DefaultAuditMaxSizeMB || *got.Audit.MaxBackups != DefaultAuditMaxBackups {
+				t.Errorf("unexpected rotation defaults %+v", got.Audit)
+			}
+		})
+	}
+}
+
+func TestLoad_Readiness(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{name: "defaults", cfg: `"upstream": {"transport": "http"}`},
+		{name: "all connected", cfg: `"upstream": {"transport": "http", "http": {"minConnected": 2}}`},
+		{name: "none connected", cfg: `"upstream": {"transport": "http", "http": {"minConnected": 0}}`},
+		{name: "more than configured", cfg: `"upstream": {"transport": "http", "http": {"minConnected": 3}}`, wantErr: true},
+		{name: "negative", cfg: `"upstream": {"transport": "http", "http": {"minConnected": -1}}`, wantErr: true},
+		{name: "probe path", cfg: `"upstream": {"transport": "http", "http": {"path": "/healthz"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [
+					{"name": "a", "transport": "stdio", "command": ["x"], "required": true},
+					{"name": "b", "transport": "stdio", "command": ["x"]}
+				],
+				` + tt.cfg + `
+			}`
+			got, err := Load(writeTemp(t, cfg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil || tt.name != "defaults" {
+				return
+			}
+			if *got.Upstream.HTTP.MinConnected != 1 {
+				t.Errorf("minConnected = %d, want 1", *got.Upstream.HTTP.MinConnected)
+			}
+			if !got.Downstream[0].Required || got.Downstream[1].Required {
+				t.Errorf("unexpected required flags %v, %v", got.Downstream[0].Required, got.Downstream[1].Required)
+			}
+		})
+	}
+}
+
+func TestLoad_Auth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    string
+		wantErr bool
+	}{
+		{name: "api keys", auth: `{"apiKeys": [{"name": "bot", "key": "k", "roles": ["support"]}, {"name": "ci", "keyFile": "ci.key"}]}`},
+		{name: "jwt", auth: `{"jwt": {"jwksFile": "jwks.json", "issuer": "https://idp.example.com"}}`},
+		{name: "empty", auth: `{}`, wantErr: true},
+		{name: "key and key file", auth: `{"apiKeys": [{"name": "bot", "key": "k", "keyFile": "k.key"}]}`, wantErr: true},
+		{name: "no key", auth: `{"apiKeys": [{"name": "bot"}]}`, wantErr: true},
+		{name: "no name", auth: `{"apiKeys": [{"key": "k"}]}`, wantErr: true},
+		{name: "duplicate name", auth: `{"apiKeys": [{"name": "bot", "key": "a"}, {"name": "bot", "key": "b"}]}`, wantErr: true},
+		{name: "jwks file and url", auth: `{"jwt": {"jwksFile": "jwks.json", "jwksURL": "https://idp.example.com/jwks"}}`, wantErr: true},
+		{name: "no jwks", auth: `{"jwt": {}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"upstream": {"transport": "http", "http": {"auth": ` + tt.auth + `}},
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}]
+			}`
+			path := writeTemp(t, cfg)
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			a := got.Upstream.HTTP.Auth
+			switch tt.name {
+			case "api keys":
+				if want := filepath.Join(filepath.Dir(path), "ci.key"); a.APIKeys[1].KeyFile != want {
+					t.Errorf("key file = %q, want %q", a.APIKeys[1].KeyFile, want)
+				}
+			case "jwt":
+				if want := filepath.Join(filepath.Dir(path), "jwks.json"); a.JWT.JWKSFile != want {
+					t.Errorf("jwks file = %q, want %q", a.JWT.JWKSFile, want)
+				}
+				if a.JWT.RolesClaim != DefaultRolesClaim {
+					t.Errorf("roles claim = %q, want %q", a.JWT.RolesClaim, DefaultRolesClaim)
+				}
+			}
+		})
+	}
+
+	t.Run("stdio upstream", func(t *testing.T) {
+		cfg := `{
+			"upstream": {"http": {"auth": {"apiKeys": [{"name": "bot", "key": "k"}]}}},
+			"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}]
+		}`
+		if _, err := Load(writeTemp(t, cfg)); err == nil {
+			t.Error("expected error for auth on the stdio upstream")
+		}
+	})
+}
+
+func TestLoad_Access(t *testing.T) {
+	const auth = `"upstream": {"transport": "http", "http": {"auth": {"apiKeys": [{"name": "bot", "key": "k"}]}}}, `
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{name: "rules", cfg: auth + `"access": {"rules": [{"roles": ["support"], "tools": ["docs/*", "*/read_*"]}, {"subjects": ["*"], "tools": ["status/ping"]}]}`},
+		{name: "no auth", cfg: `"access": {"rules": [{"roles": ["support"], "tools": ["docs/*"]}]}`, wantErr: true},
+		{name: "client certificates", cfg: `"upstream": {"transport": "http", "http": {"tls": {"certFile": "c.pem", "keyFile": "k.pem", "clientCAFile": "ca.pem"}}}, "access": {"rules": [{"roles": ["support"], "tools": ["docs/*"]}]}`},
+		{name: "no principals", cfg: auth + `"access": {"rules": [{"tools": ["docs/*"]}]}`, wantErr: true},
+		{name: "qualified subjects", cfg: auth + `"access": {"rules": [{"subjects": ["apikey:support-bot", "jwt:alice", "mtls:client"], "tools": ["*/*"]}]}`},
+		{name: "unqualified subject", cfg: auth + `"access": {"rules": [{"subjects": ["alice"], "tools": ["*/*"]}]}`, wantErr: true},
+		{name: "unknown subject method", cfg: auth + `"access": {"rules": [{"subjects": ["basic:alice"], "tools": ["*/*"]}]}`, wantErr: true},
+		{name: "empty subject", cfg: auth + `"access": {"rules": [{"subjects": ["jwt:"], "tools": ["*/*"]}]}`, wantErr: true},
+		{name: "no tools", cfg: auth + `"access": {"rules": [{"roles": ["support"]}]}`, wantErr: true},
+		{name: "missing server", cfg: auth + `"access": {"rules": [{"roles": ["support"], "tools": ["docs__*"]}]}`, wantErr: true},
+		{name: "bad pattern", cfg: auth + `"access": {"rules": [{"roles": ["support"], "tools": ["docs/[a-"]}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}],
+				` + tt.cfg + `
+			}`
+			_, err := Load(writeTemp(t, cfg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{name: "cert", cfg: `"upstream": {"transport": "http", "http": {"tls": {"certFile": "c.pem", "keyFile": "/etc/k.pem", "clientCAFile": "ca.pem"}}}`},
+		{name: "stdio", cfg: `"upstream": {"transport": "stdio", "http": {"tls": {"certFile": "c.pem", "keyFile": "k.pem"}}}`, wantErr: true},
+		{name: "missing key", cfg: `"upstream": {"transport": "http", "http": {"tls": {"certFile": "c.pem"}}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}],
+				` + tt.cfg + `
+			}`
+			path := writeTemp(t, cfg)
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			tls := got.Upstream.HTTP.TLS
+			dir := filepath.Dir(path)
+			if tls.CertFile != filepath.Join(dir, "c.pem") || tls.ClientCAFile != filepath.Join(dir, "ca.pem") {
+				t.Errorf("relative paths not resolved: %+v", tls)
+			}
+			if tls.KeyFile != "/etc/k.pem" {
+				t.Errorf("absolute key path changed to %q", tls.KeyFile)
+			}
+		})
+	}
+}
+
+func TestLoad_Admin(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{name: "defaults", cfg: `"admin": {}`},
+		{name: "shared with metrics", cfg: `"admin": {"enabled": true, "addr": ":9090"}, "metrics": {"enabled": true, "addr": ":9090"}`},
+		{name: "upstream addr", cfg: `"admin": {"enabled": true, "addr": ":8080"}, "upstream": {"transport": "http", "http": {"addr": ":8080"}}`, wantErr: true},
+		{name: "metrics under admin", cfg: `"admin": {"enabled": true, "addr": ":9090"}, "metrics": {"enabled": true, "addr": ":9090", "path": "/admin/metrics"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"]}],
+				` + tt.cfg + `
+			}`
+			got, err := Load(writeTemp(t, cfg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil || tt.name != "defaults" {
+				return
+			}
+			if *got.Admin.Enabled {
+				t.Error("admin API should be disabled by default")
+			}
+			if got.Admin.Addr != DefaultAdminAddr {
+				t.Errorf("admin addr = %q, want %q", got.Admin.Addr, DefaultAdminAddr)
+			}
+		})
+	}
+}
+
+func TestLoad_Modes(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+	}{
+		{name: "report", cfg: `"sanitization": {"mode": "report"}`},
+		{name: "scanner modes", cfg: `"sanitization": {"scannerModes": {"injection": "report", "secrets": "enforce"}}`},
+		{name: "invalid mode", cfg: `"sanitization": {"mode": "shadow"}`, wantErr: true},
+		{name: "unknown scanner", cfg: `"sanitization": {"scannerModes": {"antivirus": "report"}}`, wantErr: true},
+		{name: "invalid scanner mode", cfg: `"sanitization": {"scannerModes": {"url": "off"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := `{
+				"downstream": [{"name": "a", "transport": "stdio", "command": ["x"], "sanitization": {"mode": "enforce"}}],
+				` + tt.cfg + `
+			}`
+			_, err := Load(writeTemp(t, cfg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScannerMode(t *testing.T) {
+	global := SanitizationConfig{Mode: ModeEnforce, ScannerModes: map[string]string{"injection": ModeReport}}
+	server := Merge(&global, &SanitizationConfig{Mode: ModeReport, ScannerModes: map[string]string{"secrets": ModeEnforce}})
+
+	tests := []struct {
+		cfg     SanitizationConfig
+		scanner string
+		want    string
+	}{
+		{global, "injection", ModeReport},
+		{global, "url", ModeEnforce},
+		{server, "url", ModeReport},
+		{server, "secrets", ModeEnforce},
+		{server, "injection", ModeReport},
+		{SanitizationConfig{}, "url", ModeEnforce},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.ScannerMode(tt.scanner); got != tt.want {
+			t.Errorf("ScannerMode(%s) = %s, want %s (mode %q, scanners %v)", tt.scanner, got, tt.want, tt.cfg.Mode, tt.cfg.ScannerModes)
+		}
+	}
+	if len(global.ScannerModes) != 1 {
+		t.Error("Merge modified the global scanner modes")
+	}
+}
+
 func TestMerge_NilOverride(t *testing.T) {
 	global := SanitizationConfig{
 		MaxResponseChars: intPtr(16000),
@@ -280,6 +967,88 @@ func TestMerge_CustomPatternsOverride(t *testing.T) {
 	}
 }
 
+func TestMerge_SecretRulesOverride(t *testing.T) {
+	global := SanitizationConfig{
+		EnableSecretRedaction: boolPtr(true),
+		CustomSecretRules:     []SecretRule{{Name: "global", Pattern: "g"}},
+	}
+	override := SanitizationConfig{
+		EnableSecretRedaction: boolPtr(false),
+		CustomSecretRules:     []SecretRule{{Name: "override", Pattern: "o"}},
+	}
+
+	merged := Merge(&global, &override)
+
+	if *merged.EnableSecretRedaction {
+		t.Error("enableSecretRedaction should be false from override")
+	}
+	if len(merged.CustomSecretRules) != 1 || merged.CustomSecretRules[0].Name != "override" {
+		t.Errorf("custom secret rules = %v, want [override]", merged.CustomSecretRules)
+	}
+}
+
+func TestMerge_PIIOverride(t *testing.T) {
+	global := SanitizationConfig{
+		PII: &PIIConfig{
+			Enabled: boolPtr(false),
+			Action:  PIIActionRedact,
+			Email:   boolPtr(true),
+			Phone:   boolPtr(true),
+		},
+	}
+	override := SanitizationConfig{
+		PII: &PIIConfig{
+			Enabled: boolPtr(true),
+			Action:  PIIActionHash,
+			Phone:   boolPtr(false),
+		},
+	}
+
+	merged := Merge(&global, &override)
+
+	if !*merged.PII.Enabled {
+		t.Error("pii.enabled should be true from override")
+	}
+	if merged.PII.Action != PIIActionHash {
+		t.Errorf("pii.action = %q, want %q", merged.PII.Action, PIIActionHash)
+	}
+	if !*merged.PII.Email {
+		t.Error("pii.email should remain true from global")
+	}
+	if *merged.PII.Phone {
+		t.Error("pii.phone should be false from override")
+	}
+	if *global.PII.Enabled {
+		t.Error("global config was mutated")
+	}
+}
+
+func TestMerge_ArgumentsOverride(t *testing.T) {
+	global := SanitizationConfig{
+		Arguments: &ArgumentSanitizationConfig{
+			Enabled:             boolPtr(true),
+			EnableURLValidation: boolPtr(true),
+		},
+	}
+	override := SanitizationConfig{
+		Arguments: &ArgumentSanitizationConfig{
+			EnableURLValidation: boolPtr(false),
+		},
+	}
+
+	merged := Merge(&global, &override)
+
+	if !*merged.Arguments.Enabled {
+		t.Error("arguments.enabled should remain true from global")
+	}
+	if *merged.Arguments.EnableURLValidation {
+		t.Error("arguments.enableURLValidation should be false from override")
+	}
+	if !*global.Arguments.EnableURLValidation {
+		t.Error("global config was mutated")
+	}
+}
+
 func writeTemp(t *testing.T, content string) string {
 	t.Helper()
 	path := filepath.Join(t.TempDir(), "config.json")
@@ -0,0 +1,131 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetAccessPolicy restricts the tools, prompts and resources each
+// principal may use. Those a principal may not use are hidden from its
+// list results, and its calls, prompt gets and resource reads of them are
+// denied. Must be called before DiscoverAndRegister.
+func (r *Registry) SetAccessPolicy(p *auth.Policy) {
+	r.access = p
+	if p != nil {
+		r.upstream.Server.AddReceivingMiddleware(r.filterLists)
+	}
+}
+
+// filterLists removes the tools, prompts, resources and resource templates
+// the session's principal may not use from list results, so each session
+// sees its own catalog.
+func (r *Registry) filterLists(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if err != nil {
+			return res, err
+		}
+
+		principal := auth.PrincipalFrom(ctx)
+		switch list := res.(type) {
+		case *mcp.ListToolsResult:
+			filtered := *list
+			filtered.Tools = keep(list.Tools, func(tool *mcp.Tool) bool {
+				return r.allows(principal, tool.Name)
+			})
+			return &filtered, nil
+		case *mcp.ListPromptsResult:
+			filtered := *list
+			filtered.Prompts = keep(list.Prompts, func(prompt *mcp.Prompt) bool {
+				return r.allowsEntry(principal, r.prompts, prompt.Name)
+			})
+			return &filtered, nil
+		case *mcp.ListResourcesResult:
+			filtered := *list
+			filtered.Resources = keep(list.Resources, func(res *mcp.Resource) bool {
+				return r.allowsEntry(principal, r.resources, res.URI)
+			})
+			return &filtered, nil
+		case *mcp.ListResourceTemplatesResult:
+			filtered := *list
+			filtered.ResourceTemplates = keep(list.ResourceTemplates, func(tmpl *mcp.ResourceTemplate) bool {
+				return r.allowsEntry(principal, r.templates, tmpl.URITemplate)
+			})
+			return &filtered, nil
+		}
+		return res, nil
+	}
+}
+
+// keep returns the items for which ok is true, as a new slice.
+func keep[T any](items []T, ok func(T) bool) []T {
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		if ok(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// allows reports whether principal may call the upstream tool name.
+// Unknown tools are allowed, as the gateway registers no others.
+func (r *Registry) allows(principal *auth.Principal, upstreamName string) bool {
+	r.mu.Lock()
+	target := r.tools[r.toolOwner(upstreamName)][upstreamName]
+	r.mu.Unlock()
+
+	if target == nil {
+		return true
+	}
+	return r.access.Allows(principal, target.serverName, target.downstreamName)
+}
+
+// allowsEntry reports whether principal may use the prompt, resource or
+// resource template registered under key in entries, one of r.prompts,
+// r.resources and r.templates. The policy matches it by its downstream
+// name. Unknown entries are allowed, as for tools.
+func (r *Registry) allowsEntry(principal *auth.Principal, entries map[string]map[string]string, key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for server, names := range entries {
+		if name, ok := names[key]; ok {
+			return r.access.Allows(principal, server, name)
+		}
+	}
+	return true
+}
+
+// guardPrompt wraps handler to deny gets of the downstream prompt name on
+// serverName by principals the access policy does not grant it to.
+func (r *Registry) guardPrompt(serverName, name string, handler mcp.PromptHandler) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if !r.access.Allows(auth.PrincipalFrom(ctx), serverName, name) {
+			return nil, fmt.Errorf("prompt denied: access to %s is not granted", req.Params.Name)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// guardResource wraps handler to deny reads of the downstream resource or
+// resource template name on serverName by principals the access policy
+// does not grant it to.
+func (r *Registry) guardResource(serverName, name string, handler mcp.ResourceHandler) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		if !r.access.Allows(auth.PrincipalFrom(ctx), serverName, name) {
+			return nil, fmt.Errorf("resource read denied: access to %s is not granted", req.Params.URI)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func deniedResult(toolName string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("tool call denied: access to %s is not granted", toolName)}},
+		IsError: true,
+	}
+}
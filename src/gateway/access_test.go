@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/audit"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/auth"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/metrics"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAccessPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	servers := map[string]*mcp.Server{
+		"docs":    newTestServer(map[string]mcp.ToolHandler{"search": echoHandler("found")}),
+		"prod-db": newTestServer(map[string]mcp.ToolHandler{"query": echoHandler("rows")}),
+	}
+	var downstream []config.DownstreamConfig
+	for name := range servers {
+		downstream = append(downstream, config.DownstreamConfig{Name: name, Transport: config.TransportStdio, Command: []string{"dummy"}})
+	}
+	dm, err := transport.NewDownstreamManager(ctx, downstream, testLogger(), func(ds config.DownstreamConfig) (mcp.Transport, error) {
+		return runTestServer(ctx, servers[ds.Name]), nil
+	})
+	if err != nil {
+		t.Fatalf("NewDownstreamManager: %v", err)
+	}
+	t.Cleanup(dm.Close)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.Open(audit.Options{Path: path})
+	if err != nil {
+		t.Fatalf("audit.Open: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	upstream := transport.NewUpstream(config.UpstreamConfig{Transport: config.TransportStdio}, testLogger())
+	reg := NewRegistry(upstream, dm, minimalSanitizationConfig(), testLogger())
+	reg.SetAuditLog(log)
+	reg.SetAccessPolicy(auth.NewPolicy([]config.AccessRule{
+		{Roles: []string{"support"}, Tools: []string{"docs/*"}},
+		{Roles: []string{"dba"}, Tools: []string{"*/*"}},
+	}))
+	if _, err := reg.DiscoverAndRegister(ctx); err != nil {
+		t.Fatalf("DiscoverAndRegister: %v", err)
+	}
+
+	support := connectUpstream(t, auth.WithPrincipal(ctx, &auth.Principal{Subject: "support-bot", Method: auth.MethodAPIKey, Roles: []string{"support"}}), upstream)
+	dba := connectUpstream(t, auth.WithPrincipal(ctx, &auth.Principal{Subject: "alice", Method: auth.MethodJWT, Roles: []string{"dba"}}), upstream)
+
+	if got := listToolNames(t, ctx, support); !slices.Equal(got, []string{"docs__search"}) {
+		t.Errorf("support tools = %v, want [docs__search]", got)
+	}
+	if got := listToolNames(t, ctx, dba); !slices.Equal(got, []string{"docs__search", "prod-db__query"}) {
+		t.Errorf("dba tools = %v, want both", got)
+	}
+
+	res, err := support.CallTool(ctx, &mcp.CallToolParams{Name: "prod-db__query"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if !res.IsError {
+		t.Errorf("expected denied call, got %+v", res.Content)
+	}
+	res, err = dba.CallTool(ctx, &mcp.CallToolParams{Name: "prod-db__query"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if res.IsError || res.Content[0].(*mcp.TextContent).Text != "rows" {
+		t.Errorf("expected allowed call, got %+v", res.Content)
+	}
+
+	records := readAuditLog(t, path)
+	if len(records) != 2 {
+		t.Fatalf("got %d audit records, want 2", len(records))
+	}
+	denied := records[0]
+	if denied.Outcome != metrics.OutcomeDenied || denied.Principal == nil || denied.Principal.Subject != "support-bot" {
+		t.Errorf("denied record = %+v", denied)
+	}
+	if denied.DownstreamLatencyMS != 0 {
+		t.Error("denied call should not reach the downstream server")
+	}
+	if records[1].Outcome != metrics.OutcomeSuccess {
+		t.Errorf("allowed record outcome = %s", records[1].Outcome)
+	}
+}
+
+func TestAccessPolicy_promptsAndResources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	servers := map[string]*mcp.Server{
+		"docs": newResourceServer("contents"),
+		"hr":   newPromptServer("hello "),
+	}
+	var downstream []config.DownstreamConfig
+	for _, name := range []string{"docs", "hr"} {
+		downstream = append(downstream, config.DownstreamConfig{Name: name, Transport: config.TransportStdio, Command: []string{"dummy"}})
+	}
+	dm, err := transport.NewDownstreamManager(ctx, downstream, testLogger(), func(ds config.DownstreamConfig) (mcp.Transport, error) {
+		return runTestServer(ctx, servers[ds.Name]), nil
+	})
+	if err != nil {
+		t.Fatalf("NewDownstreamManager: %v", err)
+	}
+	t.Cleanup(dm.Close)
+
+	upstream := transport.NewUpstream(config.UpstreamConfig{Transport: config.TransportStdio}, testLogger())
+	reg := NewRegistry(upstream, dm, minimalSanitizationConfig(), testLogger())
+	reg.SetAccessPolicy(auth.NewPolicy([]config.AccessRule{
+		{Roles: []string{"support"}, Tools: []string{"docs/readme"}},
+		{Roles: []string{"hr"}, Tools: []string{"*/*"}},
+	}))
+	if _, err := reg.DiscoverAndRegister(ctx); err != nil {
+		t.Fatalf("DiscoverAndRegister: %v", err)
+	}
+
+	support := connectUpstream(t, auth.WithPrincipal(ctx, &auth.Principal{Subject: "support-bot", Method: auth.MethodAPIKey, Roles: []string{"support"}}), upstream)
+	hr := connectUpstream(t, auth.WithPrincipal(ctx, &auth.Principal{Subject: "alice", Method: auth.MethodJWT, Roles: []string{"hr"}}), upstream)
+
+	resources, err := support.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources: %v", err)
+	}
+	if len(resources.Resources) != 1 || resources.Resources[0].Name != "docs__readme" {
+		t.Errorf("support resources = %+v, want docs__readme", resources.Resources)
+	}
+	templates, err := support.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 0 {
+		t.Errorf("support templates = %+v, want none", templates.ResourceTemplates)
+	}
+	prompts, err := support.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts: %v", err)
+	}
+	if len(prompts.Prompts) != 0 {
+		t.Errorf("support prompts = %+v, want none", prompts.Prompts)
+	}
+
+	if _, err := support.ReadResource(ctx, &mcp.ReadResourceParams{URI: "mcpgw://docs/file:///readme.txt"}); err != nil {
+		t.Errorf("granted read: %v", err)
+	}
+	if _, err := support.ReadResource(ctx, &mcp.ReadResourceParams{URI: "mcpgw://docs/docs://42"}); err == nil {
+		t.Error("expected template read to be denied")
+	}
+	getGreeting := &mcp.GetPromptParams{Name: "hr__greeting", Arguments: map[string]string{"name": "bob"}}
+	if _, err := support.GetPrompt(ctx, getGreeting); err == nil {
+		t.Error("expected prompt get to be denied")
+	}
+
+	templates, err = hr.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 1 {
+		t.Errorf("hr templates = %+v, want docs__doc", templates.ResourceTemplates)
+	}
+	if _, err := hr.ReadResource(ctx, &mcp.ReadResourceParams{URI: "mcpgw://docs/docs://42"}); err != nil {
+		t.Errorf("granted template read: %v", err)
+	}
+	if _, err := hr.GetPrompt(ctx, getGreeting); err != nil {
+		t.Errorf("granted prompt get: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+)
+
+// ToolStats counts the calls made to one tool since the gateway started.
+type ToolStats struct {
+	Tool   string `json:"tool"`
+	Server string `json:"server"`
+	Calls  int64  `json:"calls"`
+	// Outcomes counts calls by outcome, one of the metrics.Outcome
+	// constants.
+	Outcomes map[string]int64 `json:"outcomes"`
+	// Verdicts counts calls by the most severe verdict the sanitizer
+	// applied, as in the audit log.
+	Verdicts map[string]int64 `json:"verdicts"`
+	// Threats counts the threats found, including by scanners in report
+	// mode.
+	Threats  int64     `json:"threats"`
+	LastCall time.Time `json:"lastCall"`
+}
+
+// recordStats adds a finished tool call to the stats of its tool.
+func (r *Registry) recordStats(target *proxyTarget, rec *callRecord, outcome string, start time.Time) {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	st, ok := r.stats[target.upstreamName]
+	if !ok {
+		st = &ToolStats{
+			Tool:     target.upstreamName,
+			Server:   target.serverName,
+			Outcomes: make(map[string]int64),
+			Verdicts: make(map[string]int64),
+		}
+		r.stats[target.upstreamName] = st
+	}
+	st.Calls++
+	st.Outcomes[outcome]++
+	st.Verdicts[rec.verdict().String()]++
+	for _, sr := range rec.scans {
+		st.Threats += int64(len(sr.Threats))
+	}
+	st.LastCall = start.UTC()
+}
+
+// Stats returns the call stats of every tool called so far, sorted by tool
+// name.
+func (r *Registry) Stats() []ToolStats {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	out := make([]ToolStats, 0, len(r.stats))
+	for _, st := range r.stats {
+		cp := *st
+		cp.Outcomes = maps.Clone(st.Outcomes)
+		cp.Verdicts = maps.Clone(st.Verdicts)
+		out = append(out, cp)
+	}
+	slices.SortFunc(out, func(a, b ToolStats) int { return cmp.Compare(a.Tool, b.Tool) })
+	return out
+}
+
+// ToolInfo describes a registered tool and the sanitization applied to it.
+type ToolInfo struct {
+	Name           string `json:"name"`
+	Server         string `json:"server"`
+	DownstreamTool string `json:"downstreamTool"`
+	// Sanitization is the effective config: the global config with the
+	// server's and the tool's overrides merged on top.
+	Sanitization config.SanitizationConfig `json:"sanitization"`
+}
+
+// Tools returns the tools currently registered, sorted by name. Secrets in
+// their sanitization config, such as the PII hash key, are redacted.
+func (r *Registry) Tools() []ToolInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []ToolInfo
+	for _, tools := range r.tools {
+		for _, target := range tools {
+			out = append(out, ToolInfo{
+				Name:           target.upstreamName,
+				Server:         target.serverName,
+				DownstreamTool: target.downstreamName,
+				Sanitization:   redactSanitization(target.pipes.cfg),
+			})
+		}
+	}
+	slices.SortFunc(out, func(a, b ToolInfo) int { return cmp.Compare(a.Name, b.Name) })
+	return out
+}
+
+const redacted = "[REDACTED]"
+
+// redactSanitization returns cfg with secrets replaced, leaving cfg itself
+// untouched.
+func redactSanitization(cfg config.SanitizationConfig) config.SanitizationConfig {
+	if cfg.PII != nil && cfg.PII.HashKey != "" {
+		pii := *cfg.PII
+		pii.HashKey = redacted
+		cfg.PII = &pii
+	}
+	return cfg
+}
+
+// AdminHandler returns the read-only admin API:
+//
+//	GET /admin/servers  downstream servers and their connection state
+//	GET /admin/tools    registered tools and their effective sanitization
+//	GET /admin/stats    per-tool call, outcome and verdict counts
+func (r *Registry) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/servers", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, struct {
+			Servers []transport.ServerStatus `json:"servers"`
+		}{r.downstream.Status()})
+	})
+	mux.HandleFunc("GET /admin/tools", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, struct {
+			Tools []ToolInfo `json:"tools"`
+		}{r.Tools()})
+	})
+	mux.HandleFunc("GET /admin/stats", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, struct {
+			Stats []ToolStats `json:"stats"`
+		}{r.Stats()})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
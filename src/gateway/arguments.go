@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+)
+
+// scanArguments decodes raw tool call arguments and runs every string value
+// through the pipeline. Arguments are never rewritten; the result is only
+// used to decide whether to block the call. Returns the blocking result, or
+// nil if the call may proceed.
+func scanArguments(
+	ctx context.Context,
+	raw json.RawMessage,
+	pipeline *sanitizer.Pipeline,
+) (*sanitizer.PipelineResult, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var args any
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("decoding arguments: %w", err)
+	}
+
+	_, blocked, err := sanitizeValue(ctx, pipeline, args)
+	return blocked, err
+}
+
+// applyArguments merges the tool's configured arguments into the raw call
+// arguments: defaults fill in missing keys and fixed values replace
+// whatever the LLM sent. Returns raw unchanged when there is nothing to
+// apply.
+func applyArguments(raw json.RawMessage, tc config.ToolConfig) (json.RawMessage, error) {
+	if len(tc.FixedArguments) == 0 && len(tc.DefaultArguments) == 0 {
+		return raw, nil
+	}
+
+	args := make(map[string]any)
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, fmt.Errorf("decoding arguments: %w", err)
+		}
+	}
+
+	for k, v := range tc.DefaultArguments {
+		if _, ok := args[k]; !ok {
+			args[k] = v
+		}
+	}
+	maps.Copy(args, tc.FixedArguments)
+
+	out, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("encoding arguments: %w", err)
+	}
+	return out, nil
+}
+
+// rewriteInputSchema adjusts a tool's input schema for its configured
+// arguments: fixed arguments are removed so the LLM never sees them, and
+// default arguments are annotated with their default and made optional.
+// The input schema is not modified.
+func rewriteInputSchema(schema any, tc config.ToolConfig) (any, error) {
+	if len(tc.FixedArguments) == 0 && len(tc.DefaultArguments) == 0 {
+		return schema, nil
+	}
+
+	v, err := normalizeJSON(schema)
+	if err != nil {
+		return nil, fmt.Errorf("input schema: %w", err)
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return schema, nil
+	}
+	out := maps.Clone(obj)
+
+	if props, ok := obj["properties"].(map[string]any); ok {
+		props = maps.Clone(props)
+		for k := range tc.FixedArguments {
+			delete(props, k)
+		}
+		for k, def := range tc.DefaultArguments {
+			if _, fixed := tc.FixedArguments[k]; fixed {
+				continue
+			}
+			prop, ok := props[k].(map[string]any)
+			if !ok {
+				continue
+			}
+			prop = maps.Clone(prop)
+			prop["default"] = def
+			props[k] = prop
+		}
+		out["properties"] = props
+	}
+
+	if required, ok := obj["required"].([]any); ok {
+		out["required"] = slices.DeleteFunc(slices.Clone(required), func(name any) bool {
+			s, _ := name.(string)
+			_, fixed := tc.FixedArguments[s]
+			_, def := tc.DefaultArguments[s]
+			return fixed || def
+		})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/audit"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/auth"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// callRecord collects what happens during one proxied tool call for the
+// metrics and the audit log. It travels in the call's context so that
+// pipeline observers can add the scans they see.
+type callRecord struct {
+	scans []sanitizer.ScanResult
+	// downstreamLatency is zero when the call did not reach the downstream
+	// server.
+	downstreamLatency time.Duration
+}
+
+type callRecordKey struct{}
+
+// withCallRecord returns ctx carrying a new, empty call record.
+func withCallRecord(ctx context.Context) (context.Context, *callRecord) {
+	rec := &callRecord{}
+	return context.WithValue(ctx, callRecordKey{}, rec), rec
+}
+
+// callRecordFrom returns the call record in ctx, or nil outside a tool call
+// (e.g. when reading resources). The methods of a nil record do nothing.
+func callRecordFrom(ctx context.Context) *callRecord {
+	rec, _ := ctx.Value(callRecordKey{}).(*callRecord)
+	return rec
+}
+
+func (c *callRecord) addScans(scans ...sanitizer.ScanResult) {
+	if c == nil {
+		return
+	}
+	c.scans = append(c.scans, scans...)
+}
+
+func (c *callRecord) setDownstreamLatency(d time.Duration) {
+	if c == nil {
+		return
+	}
+	c.downstreamLatency = d
+}
+
+// verdict returns the most severe verdict applied to the call. Boundary
+// wrapping modifies every response, so it does not count, and neither do
+// scanners in report mode.
+func (c *callRecord) verdict() sanitizer.Verdict {
+	v := sanitizer.VerdictPass
+	for _, sr := range c.scans {
+		if sr.ScannerName != "boundary" && !sr.Reported {
+			v = max(v, sr.Verdict)
+		}
+	}
+	return v
+}
+
+// scanObserver returns the observer attached to the pipelines of a server.
+// It records each scan in the metrics and in the record of the tool call in
+// progress, if any, and logs the threats found by scanners in report mode,
+// which would otherwise go unnoticed.
+func (r *Registry) scanObserver(source string) sanitizer.Observer {
+	recordMetrics := r.metrics.ScanObserver(source)
+	return func(ctx context.Context, sr sanitizer.ScanResult) {
+		if recordMetrics != nil {
+			recordMetrics(ctx, sr)
+		}
+		callRecordFrom(ctx).addScans(sr)
+
+		if sr.Reported && len(sr.Threats) > 0 {
+			r.logger.Warn("threats reported, not enforced",
+				"server", source,
+				"scanner", sr.ScannerName,
+				"verdict", sr.Verdict,
+				"threats", sr.Threats,
+			)
+		}
+	}
+}
+
+// auditRecord builds the audit log entry for a finished tool call.
+func auditRecord(
+	start time.Time,
+	req *mcp.CallToolRequest,
+	principal *auth.Principal,
+	target *proxyTarget,
+	rec *callRecord,
+	outcome string,
+	err error,
+) audit.Record {
+	r := audit.Record{
+		Time:           start.UTC(),
+		Session:        sessionID(req),
+		Principal:      principal,
+		Tool:           target.upstreamName,
+		Server:         target.serverName,
+		DownstreamTool: target.downstreamName,
+		Arguments:      req.Params.Arguments,
+		Outcome:        outcome,
+		Verdict:        rec.verdict().String(),
+	}
+	if rec.downstreamLatency > 0 {
+		r.DownstreamLatencyMS = float64(rec.downstreamLatency.Microseconds()) / 1000
+	}
+	for _, sr := range rec.scans {
+		r.Scans = append(r.Scans, audit.Scan{
+			Scanner:  sr.ScannerName,
+			Verdict:  sr.Verdict.String(),
+			Threats:  sr.Threats,
+			Reported: sr.Reported,
+		})
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// sessionID returns the ID of the upstream session that made the request,
+// empty for transports without session IDs such as stdio.
+func sessionID(req *mcp.CallToolRequest) string {
+	if req.Session == nil {
+		return ""
+	}
+	return req.Session.ID()
+}
@@ -0,0 +1,53 @@
+package gateway
+
+import (
+	"path"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// filterTool reports whether a downstream tool should be exposed upstream
+// according to the server's include/exclude patterns and annotation filter.
+// When the tool is filtered out it also returns the reason, for logging.
+func filterTool(cfg config.DownstreamConfig, tool *mcp.Tool) (bool, string) {
+	if len(cfg.Include) > 0 && !matchAny(cfg.Include, tool.Name) {
+		return false, "not included"
+	}
+	if matchAny(cfg.Exclude, tool.Name) {
+		return false, "excluded"
+	}
+
+	f := cfg.AnnotationFilter
+	if f == nil {
+		return true, ""
+	}
+
+	var a mcp.ToolAnnotations
+	if tool.Annotations != nil {
+		a = *tool.Annotations
+	}
+
+	if f.RequireReadOnly && !a.ReadOnlyHint {
+		return false, "not read-only"
+	}
+	// destructiveHint is only meaningful for tools that are not read-only.
+	if f.ExcludeDestructive && !a.ReadOnlyHint && (a.DestructiveHint == nil || *a.DestructiveHint) {
+		return false, "destructive"
+	}
+	if f.ExcludeOpenWorld && (a.OpenWorldHint == nil || *a.OpenWorldHint) {
+		return false, "open world"
+	}
+	return true, ""
+}
+
+// matchAny reports whether name matches any of the glob patterns. Patterns
+// are validated when the config is loaded.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package gateway
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestFilterTool(t *testing.T) {
+	readOnly := &mcp.ToolAnnotations{ReadOnlyHint: true}
+	additive := &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)}
+	closedWorld := &mcp.ToolAnnotations{OpenWorldHint: boolPtr(false)}
+
+	tests := []struct {
+		name string
+		cfg  config.DownstreamConfig
+		tool *mcp.Tool
+		want bool
+	}{
+		{"no filters", config.DownstreamConfig{}, &mcp.Tool{Name: "exec"}, true},
+		{"included", config.DownstreamConfig{Include: []string{"read_*"}}, &mcp.Tool{Name: "read_file"}, true},
+		{"not included", config.DownstreamConfig{Include: []string{"read_*"}}, &mcp.Tool{Name: "exec"}, false},
+		{"excluded", config.DownstreamConfig{Exclude: []string{"delete_*"}}, &mcp.Tool{Name: "delete_file"}, false},
+		{"exclude wins", config.DownstreamConfig{Include: []string{"*"}, Exclude: []string{"exec"}}, &mcp.Tool{Name: "exec"}, false},
+
+		{"destructive by default", destructiveFilter(), &mcp.Tool{Name: "t"}, false},
+		{"declared additive", destructiveFilter(), &mcp.Tool{Name: "t", Annotations: additive}, true},
+		{"read-only is not destructive", destructiveFilter(), &mcp.Tool{Name: "t", Annotations: readOnly}, true},
+
+		{"require read-only", readOnlyFilter(), &mcp.Tool{Name: "t", Annotations: additive}, false},
+		{"read-only passes", readOnlyFilter(), &mcp.Tool{Name: "t", Annotations: readOnly}, true},
+
+		{"open world by default", openWorldFilter(), &mcp.Tool{Name: "t"}, false},
+		{"closed world passes", openWorldFilter(), &mcp.Tool{Name: "t", Annotations: closedWorld}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := filterTool(tt.cfg, tt.tool)
+			if got != tt.want {
+				t.Errorf("filterTool = %v (%s), want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func destructiveFilter() config.DownstreamConfig {
+	return config.DownstreamConfig{AnnotationFilter: &config.AnnotationFilter{ExcludeDestructive: true}}
+}
+
+func readOnlyFilter() config.DownstreamConfig {
+	return config.DownstreamConfig{AnnotationFilter: &config.AnnotationFilter{RequireReadOnly: true}}
+}
+
+func openWorldFilter() config.DownstreamConfig {
+	return config.DownstreamConfig{AnnotationFilter: &config.AnnotationFilter{ExcludeOpenWorld: true}}
+}
+
+func TestRegisterServer_filtersTools(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := newTestServer(map[string]mcp.ToolHandler{
+		"read_file":   echoHandler("ok"),
+		"write_file":  echoHandler("ok"),
+		"delete_file": echoHandler("ok"),
+		"exec":        echoHandler("ok"),
+	})
+
+	session := setupGatewayConfigs(t, ctx, map[string]*mcp.Server{"fs": srv}, map[string]config.DownstreamConfig{
+		"fs": {
+			Include: []string{"*_file"},
+			Exclude: []string{"delete_*"},
+		},
+	}, minimalSanitizationConfig())
+
+	got := listToolNames(t, ctx, session)
+	want := []string{"fs__read_file", "fs__write_file"}
+	if !slices.Equal(got, want) {
+		t.Errorf("tools = %v, want %v", got, want)
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "fs__exec"})
+	if err == nil && !result.IsError {
+		t.Error("expected filtered tool to be uncallable")
+	}
+}
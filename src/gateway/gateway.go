@@ -4,10 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"net/http"
 	"os/signal"
+	"slices"
 	"syscall"
+	"time"
 
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/audit"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/auth"
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/metrics"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/pinning"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/tracing"
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
 )
 
@@ -33,8 +43,9 @@ func NewWithTransportFactory(cfg config.Config, logger *slog.Logger, factory tra
 }
 
 // Run starts the gateway: connects downstream, discovers tools, registers
-// proxied handlers, and starts the upstream server. Blocks until SIGINT/
-// SIGTERM or ctx cancellation.
+// proxied handlers, and starts the upstream server. Tools are re-discovered
+// in the background when a downstream reports changes or reconnects. Blocks
+// until SIGINT/SIGTERM or ctx cancellation.
 func (g *Gateway) Run(ctx context.Context) error {
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -50,16 +61,186 @@ func (g *Gateway) Run(ctx context.Context) error {
 
 	// 2. Create upstream server.
 	upstream := transport.NewUpstream(g.cfg.Upstream, g.logger)
+	authns, err := g.upstreamAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	if len(authns) > 0 {
+		upstream.Use(auth.Middleware(authns, g.logger.With("area", "auth")))
+	}
 
 	// 3. Discover tools and register proxied handlers.
 	reg := NewRegistry(upstream, dm, g.cfg.Sanitization, g.logger)
+	reg.SetAccessPolicy(auth.NewPolicy(g.cfg.Access.Rules))
+
+	health := newProbes(dm, g.cfg)
+	upstream.Handle("GET "+config.HealthzPath, http.HandlerFunc(health.live))
+	upstream.Handle("GET "+config.ReadyzPath, http.HandlerFunc(health.ready))
+
+	// Metrics and the admin API may share a listener when configured with
+	// the same address.
+	side := make(listeners)
+
+	if deref(g.cfg.Metrics.Enabled) {
+		m := metrics.New()
+		dm.SetMetrics(m)
+		reg.SetMetrics(m)
+		if addr := g.cfg.Metrics.Addr; addr != "" {
+			side.handle(addr, g.cfg.Metrics.Path, m.Handler())
+		} else {
+			upstream.Handle(g.cfg.Metrics.Path, m.Handler())
+			g.logger.Info("serving metrics on upstream listener", "path", g.cfg.Metrics.Path)
+		}
+	}
+
+	if deref(g.cfg.Admin.Enabled) {
+		side.handle(g.cfg.Admin.Addr, "/admin/", reg.AdminHandler())
+	}
+
+	if err := g.serveListeners(ctx, side); err != nil {
+		return err
+	}
+
+	if deref(g.cfg.Tracing.Enabled) {
+		tp, err := tracing.NewProvider(ctx, g.cfg.Tracing)
+		if err != nil {
+			return fmt.Errorf("tracing: %w", err)
+		}
+		defer func() {
+			// ctx is already cancelled; give buffered spans a moment to flush.
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tp.Shutdown(shutdownCtx); err != nil {
+				g.logger.Error("flushing traces", "err", err)
+			}
+		}()
+		reg.SetTracerProvider(tp)
+		g.logger.Info("tracing enabled", "exporter", g.cfg.Tracing.Exporter)
+	}
+
+	if deref(g.cfg.Audit.Enabled) {
+		log, err := openAuditLog(g.cfg.Audit)
+		if err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+		defer func() {
+			if err := log.Close(); err != nil {
+				g.logger.Error("closing audit log", "err", err)
+			}
+		}()
+		reg.SetAuditLog(log)
+		g.logger.Info("audit log enabled", "path", g.cfg.Audit.Path)
+	}
+
+	var pins *pinning.Lockfile
+	if g.cfg.Pinning.Policy == config.PinningWarn || g.cfg.Pinning.Policy == config.PinningBlock {
+		pins, err = pinning.Load(g.cfg.Pinning.LockFile)
+		if err != nil {
+			return fmt.Errorf("pinning: %w", err)
+		}
+		reg.SetPinning(pins, g.cfg.Pinning.Policy)
+	}
+
 	count, err := reg.DiscoverAndRegister(ctx)
 	if err != nil {
 		return fmt.Errorf("registry: %w", err)
 	}
 	g.logger.Info("tool discovery complete", "total", count)
+	health.discovered.Store(true)
+	if pins != nil {
+		if changed := pins.Pending(); len(changed) > 0 {
+			g.logger.Warn("tool definitions changed since pinned, review and run \"easymcpgateway approve\"",
+				"tools", changed,
+				"policy", g.cfg.Pinning.Policy,
+			)
+		}
+	}
+	if pending := dm.Pending(); len(pending) > 0 {
+		g.logger.Warn("downstream servers pending, tools will be registered once connected",
+			"servers", pending,
+		)
+	}
+	go reg.Watch(ctx)
 
 	// 4. Start upstream (blocks until ctx cancelled).
 	g.logger.Info("upstream ready", "transport", g.cfg.Upstream.Transport)
 	return upstream.Run(ctx)
 }
+
+// upstreamAuth builds the authenticators of the HTTP upstream: the
+// configured credentials, then verified client certificates. It returns
+// none when clients need not authenticate.
+func (g *Gateway) upstreamAuth(ctx context.Context) ([]auth.Authenticator, error) {
+	if g.cfg.Upstream.Transport != config.TransportHTTP {
+		return nil, nil
+	}
+	var authns []auth.Authenticator
+	if a := g.cfg.Upstream.HTTP.Auth; a != nil {
+		var err error
+		if authns, err = auth.New(ctx, *a); err != nil {
+			return nil, err
+		}
+		g.logger.Info("upstream authentication enabled", "apiKeys", len(a.APIKeys), "jwt", a.JWT != nil)
+	}
+	if t := g.cfg.Upstream.HTTP.TLS; t != nil && t.ClientCAFile != "" {
+		authns = append(authns, auth.ClientCert{})
+		g.logger.Info("upstream client certificate authentication enabled", "clientCA", t.ClientCAFile)
+	}
+	return authns, nil
+}
+
+// listeners collects the handlers served outside the upstream listener,
+// one mux per address.
+type listeners map[string]*http.ServeMux
+
+func (l listeners) handle(addr, pattern string, handler http.Handler) {
+	mux, ok := l[addr]
+	if !ok {
+		mux = http.NewServeMux()
+		l[addr] = mux
+	}
+	mux.Handle(pattern, handler)
+}
+
+// serveListeners listens on every address in l and serves its handlers in
+// the background until ctx is cancelled.
+func (g *Gateway) serveListeners(ctx context.Context, l listeners) error {
+	for _, addr := range slices.Sorted(maps.Keys(l)) {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("listen %s: %w", addr, err)
+		}
+		g.logger.Info("serving side listener", "addr", ln.Addr())
+
+		mux := l[addr]
+		go func() {
+			if err := transport.Serve(ctx, ln, mux); err != nil {
+				g.logger.Error("side listener stopped", "addr", addr, "err", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// openAuditLog opens the audit log described by cfg. The config has already
+// been validated, so MaxAge parses.
+func openAuditLog(cfg config.AuditConfig) (*audit.Log, error) {
+	var maxAge time.Duration
+	if cfg.MaxAge != "" {
+		maxAge, _ = time.ParseDuration(cfg.MaxAge)
+	}
+	var maxSizeMB, maxBackups int
+	if cfg.MaxSizeMB != nil {
+		maxSizeMB = *cfg.MaxSizeMB
+	}
+	if cfg.MaxBackups != nil {
+		maxBackups = *cfg.MaxBackups
+	}
+	return audit.Open(audit.Options{
+		Path:       cfg.Path,
+		MaxSize:    int64(maxSizeMB) << 20,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+		HashKey:    []byte(cfg.HashKey),
+	})
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+)
+
+// probes serves the liveness and readiness endpoints of the HTTP upstream.
+type probes struct {
+	downstream *transport.DownstreamManager
+	// required lists the servers that must be connected to be ready.
+	required     []string
+	minConnected int
+
+	// discovered is set once the initial tool discovery has finished.
+	discovered atomic.Bool
+}
+
+func newProbes(dm *transport.DownstreamManager, cfg config.Config) *probes {
+	p := &probes{downstream: dm, minConnected: 1}
+	if n := cfg.Upstream.HTTP.MinConnected; n != nil {
+		p.minConnected = *n
+	}
+	for _, ds := range cfg.Downstream {
+		if ds.Required {
+			p.required = append(p.required, ds.Name)
+		}
+	}
+	return p
+}
+
+// readiness is the body of a /readyz response.
+type readiness struct {
+	Ready      bool `json:"ready"`
+	Discovered bool `json:"discovered"`
+	Connected  int  `json:"connected"`
+	// Missing lists the required servers that are not connected.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// check reports whether the gateway is ready: discovery has finished, every
+// required server is connected and at least minConnected servers are.
+func (p *probes) check() readiness {
+	r := readiness{Discovered: p.discovered.Load()}
+	connected := p.downstream.Connected()
+	for _, ok := range connected {
+		if ok {
+			r.Connected++
+		}
+	}
+	for _, name := range p.required {
+		if !connected[name] {
+			r.Missing = append(r.Missing, name)
+		}
+	}
+	r.Ready = r.Discovered && len(r.Missing) == 0 && r.Connected >= p.minConnected
+	return r
+}
+
+// live reports whether the downstream health check loop is running; a
+// stuck loop means the gateway can no longer recover lost servers.
+func (p *probes) live(w http.ResponseWriter, _ *http.Request) {
+	alive := p.downstream.Alive()
+	writeJSON(w, probeStatus(alive), struct {
+		Alive bool `json:"alive"`
+	}{alive})
+}
+
+func (p *probes) ready(w http.ResponseWriter, _ *http.Request) {
+	r := p.check()
+	writeJSON(w, probeStatus(r.Ready), r)
+}
+
+func probeStatus(ok bool) int {
+	if ok {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
@@ -0,0 +1,122 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestProbes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := newTestServer(map[string]mcp.ToolHandler{"hello": echoHandler("world")})
+	factory := func(ds config.DownstreamConfig) (mcp.Transport, error) {
+		if ds.Name == "down" {
+			return nil, errors.New("connection refused")
+		}
+		return runTestServer(ctx, srv), nil
+	}
+	downstream := []config.DownstreamConfig{
+		{Name: "up", Transport: config.TransportStdio, Command: []string{"dummy"}},
+		{Name: "down", Transport: config.TransportStdio, Command: []string{"dummy"}},
+	}
+	dm, err := transport.NewDownstreamManager(ctx, downstream, testLogger(), factory)
+	if err != nil {
+		t.Fatalf("NewDownstreamManager: %v", err)
+	}
+	t.Cleanup(dm.Close)
+
+	tests := []struct {
+		name         string
+		required     []string
+		minConnected int
+		discovered   bool
+		wantReady    bool
+		wantMissing  []string
+	}{
+		{name: "ready", minConnected: 1, discovered: true, wantReady: true},
+		{name: "discovery pending", minConnected: 1},
+		{name: "too few connected", minConnected: 2, discovered: true},
+		{name: "required connected", required: []string{"up"}, minConnected: 1, discovered: true, wantReady: true},
+		{name: "required missing", required: []string{"up", "down"}, discovered: true, wantMissing: []string{"down"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{Downstream: slices.Clone(downstream)}
+			cfg.Upstream.HTTP.MinConnected = &tt.minConnected
+			for i, ds := range cfg.Downstream {
+				cfg.Downstream[i].Required = slices.Contains(tt.required, ds.Name)
+			}
+			p := newProbes(dm, cfg)
+			p.discovered.Store(tt.discovered)
+
+			rec := httptest.NewRecorder()
+			p.ready(rec, httptest.NewRequest(http.MethodGet, config.ReadyzPath, nil))
+
+			var got readiness
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if got.Ready != tt.wantReady || !slices.Equal(got.Missing, tt.wantMissing) {
+				t.Errorf("readiness = %+v, want ready %v, missing %v", got, tt.wantReady, tt.wantMissing)
+			}
+			if want := probeStatus(tt.wantReady); rec.Code != want {
+				t.Errorf("status = %d, want %d", rec.Code, want)
+			}
+			if got.Connected != 1 {
+				t.Errorf("connected = %d, want 1", got.Connected)
+			}
+		})
+	}
+
+	t.Run("live", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newProbes(dm, config.Config{}).live(rec, httptest.NewRequest(http.MethodGet, config.HealthzPath, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestProbes_noServerReachable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dm, err := transport.NewDownstreamManager(ctx, []config.DownstreamConfig{
+		{Name: "late", Transport: config.TransportStdio, Command: []string{"dummy"}},
+	}, testLogger(), func(config.DownstreamConfig) (mcp.Transport, error) {
+		return nil, errors.New("connection refused")
+	})
+	if err != nil {
+		t.Fatalf("NewDownstreamManager: %v", err)
+	}
+	t.Cleanup(dm.Close)
+
+	upstream := transport.NewUpstream(config.UpstreamConfig{Transport: config.TransportStdio}, testLogger())
+	reg := NewRegistry(upstream, dm, minimalSanitizationConfig(), testLogger())
+	count, err := reg.DiscoverAndRegister(ctx)
+	if err != nil || count != 0 {
+		t.Fatalf("DiscoverAndRegister = %d, %v; want 0, nil", count, err)
+	}
+
+	p := newProbes(dm, config.Config{})
+	p.discovered.Store(true)
+	if r := p.check(); r.Ready || r.Connected != 0 {
+		t.Errorf("readiness = %+v, want not ready", r)
+	}
+
+	session := connectUpstream(t, ctx, upstream)
+	if names := listToolNames(t, ctx, session); len(names) != 0 {
+		t.Errorf("tools = %v, want none", names)
+	}
+}
@@ -0,0 +1,214 @@
+package gateway
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/pinning"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// setupPinnedRegistry connects to a single downstream server named "srv",
+// registers its tools with pinning enabled and returns an upstream session
+// along with the registry.
+func setupPinnedRegistry(
+	t *testing.T,
+	ctx context.Context,
+	srv *mcp.Server,
+	pins *pinning.Lockfile,
+	policy string,
+) (*mcp.ClientSession, *Registry) {
+	t.Helper()
+
+	dm, err := transport.NewDownstreamManager(ctx, []config.DownstreamConfig{
+		{Name: "srv", Transport: config.TransportStdio, Command: []string{"dummy"}},
+	}, testLogger(), func(config.DownstreamConfig) (mcp.Transport, error) {
+		return runTestServer(ctx, srv), nil
+	})
+	if err != nil {
+		t.Fatalf("NewDownstreamManager: %v", err)
+	}
+	t.Cleanup(dm.Close)
+
+	upstream := transport.NewUpstream(config.UpstreamConfig{Transport: config.TransportStdio}, testLogger())
+	reg := NewRegistry(upstream, dm, minimalSanitizationConfig(), testLogger())
+	reg.SetPinning(pins, policy)
+	if _, err := reg.DiscoverAndRegister(ctx); err != nil {
+		t.Fatalf("DiscoverAndRegister: %v", err)
+	}
+	return connectUpstream(t, ctx, upstream), reg
+}
+
+// pinnedLockfile returns a lockfile in which srv__stable is pinned to its
+// current definition and srv__changed to a different one.
+func pinnedLockfile(t *testing.T) *pinning.Lockfile {
+	t.Helper()
+
+	pins, err := pinning.Load(filepath.Join(t.TempDir(), "tools.lock.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stable, err := pinning.Fingerprint(&mcp.Tool{
+		Name:        "stable",
+		Description: "test tool stable",
+		InputSchema: map[string]any{"type": "object"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pins.Check("srv__stable", stable)
+	pins.Check("srv__changed", "sha256:approved-definition")
+	return pins
+}
+
+func pinnedTestServer() *mcp.Server {
+	return newTestServer(map[string]mcp.ToolHandler{
+		"stable":  echoHandler("stable"),
+		"changed": echoHandler("changed"),
+		"new":     echoHandler("new"),
+	})
+}
+
+func TestPinning_blockPolicyRefusesChangedTools(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pins := pinnedLockfile(t)
+	session, _ := setupPinnedRegistry(t, ctx, pinnedTestServer(), pins, config.PinningBlock)
+
+	if names := listToolNames(t, ctx, session); !slices.Equal(names, []string{"srv__new", "srv__stable"}) {
+		t.Errorf("tools = %v, want [srv__new srv__stable]", names)
+	}
+	if pending := pins.Pending(); !slices.Equal(pending, []string{"srv__changed"}) {
+		t.Errorf("pending = %v, want [srv__changed]", pending)
+	}
+}
+
+func TestPinning_warnPolicyRegistersChangedTools(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pins := pinnedLockfile(t)
+	session, _ := setupPinnedRegistry(t, ctx, pinnedTestServer(), pins, config.PinningWarn)
+
+	if names := listToolNames(t, ctx, session); !slices.Equal(names, []string{"srv__changed", "srv__new", "srv__stable"}) {
+		t.Errorf("tools = %v, want all three", names)
+	}
+	if pending := pins.Pending(); !slices.Equal(pending, []string{"srv__changed"}) {
+		t.Errorf("pending = %v, want [srv__changed]", pending)
+	}
+}
+
+func TestPinning_persistsLockfile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "tools.lock.json")
+	pins, err := pinning.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setupPinnedRegistry(t, ctx, pinnedTestServer(), pins, config.PinningBlock)
+
+	reloaded, err := pinning.Load(path)
+	if err != nil {
+		t.Fatalf("reloading lockfile: %v", err)
+	}
+	fp, err := pinning.Fingerprint(&mcp.Tool{
+		Name:        "stable",
+		Description: "test tool stable",
+		InputSchema: map[string]any{"type": "object"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Check("srv__stable", fp); got != pinning.StatusMatch {
+		t.Errorf("reloaded status = %v, want match", got)
+	}
+}
+
+func TestPinning_approvalWhileRunningSurvivesRefresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pins := pinnedLockfile(t)
+	if err := pins.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv := pinnedTestServer()
+	session, reg := setupPinnedRegistry(t, ctx, srv, pins, config.PinningBlock)
+
+	// The approve command runs in its own process with its own copy.
+	cli, err := pinning.Load(pins.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Approve("srv__changed"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if err := cli.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A new tool makes the running registry save the lockfile again.
+	srv.AddTool(&mcp.Tool{
+		Name:        "added",
+		InputSchema: map[string]any{"type": "object"},
+	}, echoHandler("added"))
+	if err := reg.Refresh(ctx, "srv"); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	want := []string{"srv__added", "srv__changed", "srv__new", "srv__stable"}
+	if names := listToolNames(t, ctx, session); !slices.Equal(names, want) {
+		t.Errorf("tools = %v, want %v", names, want)
+	}
+
+	reloaded, err := pinning.Load(pins.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := reloaded.Pending(); len(pending) != 0 {
+		t.Errorf("pending = %v, want the approval kept", pending)
+	}
+}
+
+func TestPinning_approvalRegistersToolWithoutListChanged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pins := pinnedLockfile(t)
+	if err := pins.Save(); err != nil {
+		t.Fatal(err)
+	}
+	session, reg := setupPinnedRegistry(t, ctx, pinnedTestServer(), pins, config.PinningBlock)
+
+	// Nothing changes until the lockfile does.
+	reg.reloadPins(ctx)
+	if names := listToolNames(t, ctx, session); slices.Contains(names, "srv__changed") {
+		t.Fatalf("tools = %v, want srv__changed refused", names)
+	}
+
+	cli, err := pinning.Load(pins.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Approve("srv__changed"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if err := cli.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The downstream server stays quiet; the lockfile change alone
+	// registers the approved tool.
+	reg.reloadPins(ctx)
+	want := []string{"srv__changed", "srv__new", "srv__stable"}
+	if names := listToolNames(t, ctx, session); !slices.Equal(names, want) {
+		t.Errorf("tools = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,228 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerPrompts discovers prompts from a downstream session, registers
+// namespaced proxies on the upstream server, and removes any previously
+// registered prompts the server no longer offers. Returns the number of
+// prompts registered.
+func (r *Registry) registerPrompts(
+	ctx context.Context,
+	conn *transport.DownstreamConn,
+	pipeline *sanitizer.Pipeline,
+) (int, error) {
+	serverName := conn.Name
+	prompts, err := r.addPrompts(ctx, conn, pipeline)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if removed := removedKeys(r.prompts[serverName], prompts); len(removed) > 0 {
+		r.upstream.Server.RemovePrompts(removed...)
+		r.logger.Info("removed prompts", "server", serverName, "prompts", removed)
+	}
+	r.prompts[serverName] = prompts
+
+	return len(prompts), nil
+}
+
+// addPrompts registers a proxy for each prompt of the downstream server,
+// named behind the server's prefix and separator as its tools are. Prompt
+// and argument titles and descriptions are sanitized first; prompts whose
+// metadata is blocked, or whose name another server has already registered,
+// are refused registration. It returns the upstream names registered, each
+// mapped to its downstream name. Servers that do not advertise the prompts
+// capability have none.
+func (r *Registry) addPrompts(
+	ctx context.Context,
+	conn *transport.DownstreamConn,
+	pipeline *sanitizer.Pipeline,
+) (map[string]string, error) {
+	serverName := conn.Name
+	prompts := make(map[string]string)
+	if !hasPrompts(conn.Session) {
+		return prompts, nil
+	}
+
+	fields := pipeline.Without("boundary")
+
+	for prompt, err := range conn.Session.Prompts(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("listing prompts: %w", err)
+		}
+
+		namespacedName := prefixedName(conn.Config, prompt.Name)
+		if owner := r.promptOwner(namespacedName); owner != "" && owner != serverName {
+			r.logger.Error("refused prompt registration: name already registered",
+				"server", serverName,
+				"prompt", prompt.Name,
+				"name", namespacedName,
+				"owner", owner,
+			)
+			continue
+		}
+
+		proxied := proxyPrompt(prompt, namespacedName)
+		blocked, err := sanitizePromptDefinition(ctx, proxied, fields)
+		if err != nil {
+			return nil, fmt.Errorf("sanitizing prompt %s: %w", prompt.Name, err)
+		}
+		if blocked != nil {
+			r.logger.Warn("refused prompt registration",
+				"server", serverName,
+				"prompt", prompt.Name,
+				"threats", blocked.AllThreats,
+			)
+			continue
+		}
+		handler := proxyPromptHandler(r.downstream, serverName, prompt.Name, namespacedName, pipeline, r.logger)
+		r.upstream.Server.AddPrompt(proxied, r.guardPrompt(serverName, prompt.Name, handler))
+
+		prompts[namespacedName] = prompt.Name
+	}
+	return prompts, nil
+}
+
+// promptOwner returns the server that has registered the upstream prompt
+// name, or "" if none has.
+func (r *Registry) promptOwner(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for server, names := range r.prompts {
+		if _, ok := names[name]; ok {
+			return server
+		}
+	}
+	return ""
+}
+
+func hasPrompts(session *mcp.ClientSession) bool {
+	init := session.InitializeResult()
+	return init != nil && init.Capabilities != nil && init.Capabilities.Prompts != nil
+}
+
+// proxyPrompt creates a copy of the downstream prompt, and of its
+// arguments, with a namespaced name.
+func proxyPrompt(original *mcp.Prompt, namespacedName string) *mcp.Prompt {
+	args := make([]*mcp.PromptArgument, len(original.Arguments))
+	for i, arg := range original.Arguments {
+		a := *arg
+		args[i] = &a
+	}
+	return &mcp.Prompt{
+		Name:        namespacedName,
+		Title:       original.Title,
+		Description: original.Description,
+		Arguments:   args,
+	}
+}
+
+// sanitizePromptDefinition runs the title and description of a proxied
+// prompt and of each of its arguments through the pipeline, in place. The
+// pipeline should not include the boundary scanner. It returns the blocking
+// result if any field was blocked, in which case the prompt must not be
+// registered.
+func sanitizePromptDefinition(
+	ctx context.Context,
+	prompt *mcp.Prompt,
+	pipeline *sanitizer.Pipeline,
+) (*sanitizer.PipelineResult, error) {
+	blocked, err := sanitizeFields(ctx, pipeline, &prompt.Title, &prompt.Description)
+	if err != nil || blocked != nil {
+		return blocked, err
+	}
+	for _, arg := range prompt.Arguments {
+		blocked, err := sanitizeFields(ctx, pipeline, &arg.Title, &arg.Description)
+		if err != nil || blocked != nil {
+			return blocked, err
+		}
+	}
+	return nil, nil
+}
+
+// proxyPromptHandler returns a PromptHandler that forwards GetPrompt to the
+// downstream session with the original prompt name, then sanitizes the
+// returned messages.
+func proxyPromptHandler(
+	dm *transport.DownstreamManager,
+	serverName string,
+	downstreamName string,
+	namespacedName string,
+	pipeline *sanitizer.Pipeline,
+	logger *slog.Logger,
+) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		session := dm.Session(serverName)
+		if session == nil {
+			return nil, fmt.Errorf("downstream %s not connected", serverName)
+		}
+
+		result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+			Name:      downstreamName,
+			Arguments: req.Params.Arguments,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("downstream prompt %s: %w", namespacedName, err)
+		}
+
+		return sanitizePromptResult(ctx, serverName, namespacedName, result, pipeline, logger)
+	}
+}
+
+// sanitizePromptResult runs the result description and the content of each
+// prompt message through the pipeline, walking every content type as tool
+// results do, resource URIs included. The description and resource link
+// metadata skip the boundary scanner.
+// On Block: the whole GetPrompt fails with an error describing the threats.
+// On Modify: the affected text is replaced with the sanitized version.
+func sanitizePromptResult(
+	ctx context.Context,
+	serverName string,
+	namespacedName string,
+	result *mcp.GetPromptResult,
+	pipeline *sanitizer.Pipeline,
+	logger *slog.Logger,
+) (*mcp.GetPromptResult, error) {
+	fields := pipeline.Without("boundary")
+
+	blocked, err := sanitizeFields(ctx, fields, &result.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, msg := range result.Messages {
+		if blocked != nil {
+			break
+		}
+
+		var content mcp.Content
+		content, blocked, err = sanitizeContent(ctx, serverName, msg.Content, pipeline, fields)
+		if err != nil {
+			return nil, err
+		}
+		if blocked == nil && content != msg.Content {
+			result.Messages[i] = &mcp.PromptMessage{Role: msg.Role, Content: content}
+		}
+	}
+
+	if blocked != nil {
+		logger.Warn("blocked prompt",
+			"prompt", namespacedName,
+			"threats", blocked.AllThreats,
+		)
+		return nil, fmt.Errorf("prompt %s: %s", namespacedName, blockReason(*blocked))
+	}
+
+	return result, nil
+}
@@ -1,29 +1,76 @@
 // Package gateway wires upstream and downstream transports together,
-// proxying tool calls through a sanitization pipeline.
+// proxying tool calls, resource reads and prompts through a sanitization
+// pipeline.
 package gateway
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/audit"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/auth"
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/metrics"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/pinning"
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/tracing"
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 const namespaceSep = "__"
 
-// Registry discovers tools from downstream servers, namespaces them, and
-// registers proxy handlers on the upstream server. Each proxy call runs
-// responses through the sanitization pipeline.
+// Registry discovers tools, resources and prompts from downstream servers,
+// namespaces them, and registers proxy handlers on the upstream server. Each
+// proxy call runs responses through the sanitization pipeline.
 type Registry struct {
 	upstream   *transport.Upstream
 	downstream *transport.DownstreamManager
 	globalCfg  config.SanitizationConfig
 	logger     *slog.Logger
+
+	mu sync.Mutex
+	// pipelineCache holds the sanitization pipelines built for each server.
+	pipelineCache map[string]*serverPipelines
+	// tools records the tools registered per server, keyed by namespaced
+	// name, so that re-discovery can remove tools that have disappeared.
+	tools map[string]map[string]*proxyTarget
+	// resources, templates and prompts record the resource URIs, URI
+	// templates and prompt names registered per server, each mapped to its
+	// downstream name, for the same reason.
+	resources map[string]map[string]string
+	templates map[string]map[string]string
+	prompts   map[string]map[string]string
+
+	// pins is nil when pinning is off.
+	pins      *pinning.Lockfile
+	pinPolicy string
+
+	// metrics is nil when metrics are disabled.
+	metrics *metrics.Metrics
+	// tracer starts the span of each proxied tool call; a no-op tracer when
+	// tracing is disabled.
+	tracer trace.Tracer
+	// audit is nil when the audit log is disabled.
+	audit *audit.Log
+	// access is nil when every client may use every tool.
+	access *auth.Policy
+
+	statsMu sync.Mutex
+	// stats holds the call stats of each tool, keyed by namespaced name. It
+	// survives re-discovery so counts are not lost when a server reconnects.
+	stats map[string]*ToolStats
 }
 
 // NewRegistry creates a registry wired to the given upstream/downstream pair.
@@ -34,154 +81,880 @@ func NewRegistry(
 	logger *slog.Logger,
 ) *Registry {
 	return &Registry{
-		upstream:   upstream,
-		downstream: downstream,
-		globalCfg:  globalCfg,
-		logger:     logger.With("area", "registry"),
+		upstream:      upstream,
+		downstream:    downstream,
+		globalCfg:     globalCfg,
+		logger:        logger.With("area", "registry"),
+		pipelineCache: make(map[string]*serverPipelines),
+		tools:         make(map[string]map[string]*proxyTarget),
+		resources:     make(map[string]map[string]string),
+		templates:     make(map[string]map[string]string),
+		prompts:       make(map[string]map[string]string),
+		stats:         make(map[string]*ToolStats),
+		tracer:        noop.NewTracerProvider().Tracer(tracing.ScopeName),
 	}
 }
 
-// DiscoverAndRegister iterates all downstream connections, discovers their
-// tools, and registers namespaced proxy handlers on the upstream server.
-// Returns the total number of tools registered.
-func (r *Registry) DiscoverAndRegister(ctx context.Context) (int, error) {
-	total := 0
+// SetPinning enables tool definition pinning. Must be called before
+// DiscoverAndRegister. policy is config.PinningWarn or config.PinningBlock.
+func (r *Registry) SetPinning(pins *pinning.Lockfile, policy string) {
+	r.pins = pins
+	r.pinPolicy = policy
+}
 
-	for name, conn := range r.downstream.Conns() {
-		merged := config.Merge(&r.globalCfg, conn.Config.Sanitization)
+// SetMetrics records tool calls and sanitizer verdicts in m. Must be called
+// before DiscoverAndRegister.
+func (r *Registry) SetMetrics(m *metrics.Metrics) {
+	r.metrics = m
+}
 
-		pipeline, err := BuildPipeline(merged, name)
-		if err != nil {
-			return total, fmt.Errorf("building pipeline for %s: %w", name, err)
-		}
+// SetAuditLog records every proxied tool call in l. Must be called before
+// DiscoverAndRegister.
+func (r *Registry) SetAuditLog(l *audit.Log) {
+	r.audit = l
+}
+
+// SetTracerProvider traces proxied tool calls with tp. Must be called
+// before DiscoverAndRegister.
+func (r *Registry) SetTracerProvider(tp trace.TracerProvider) {
+	r.tracer = tp.Tracer(tracing.ScopeName)
+}
+
+// DiscoverAndRegister iterates all downstream connections in config order,
+// discovers their tools, resources and prompts, and registers namespaced
+// proxy handlers on the upstream server. Where upstream names collide, the
+// server listed first keeps the name, so the outcome is the same on every
+// start. Returns the total number of tools registered. Finding no tools is
+// an error only when no server is pending; pending servers are discovered
+// by Watch once they connect.
+func (r *Registry) DiscoverAndRegister(ctx context.Context) (int, error) {
+	total := 0
 
-		count, err := r.registerServer(ctx, name, conn.Session, pipeline)
+	for _, conn := range r.downstream.ConnsInOrder() {
+		count, err := r.discoverServer(ctx, conn)
 		if err != nil {
-			return total, fmt.Errorf("registering tools for %s: %w", name, err)
+			return total, err
 		}
-
-		r.logger.Info("registered tools", "server", name, "count", count)
 		total += count
 	}
 
-	if total == 0 {
+	if total == 0 && len(r.downstream.Pending()) == 0 {
 		return 0, fmt.Errorf("no tools discovered from any downstream server")
 	}
 	return total, nil
 }
 
-func (r *Registry) registerServer(
-	ctx context.Context,
-	serverName string,
-	session *mcp.ClientSession,
-	pipeline *sanitizer.Pipeline,
-) (int, error) {
-	count := 0
-	for tool, err := range session.Tools(ctx, nil) {
+// discoverServer registers the tools, resources and prompts of a single
+// downstream server. Returns the number of tools registered.
+func (r *Registry) discoverServer(ctx context.Context, conn *transport.DownstreamConn) (int, error) {
+	name := conn.Name
+
+	pipes, err := r.pipelines(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.registerServer(ctx, conn)
+	if err != nil {
+		return 0, fmt.Errorf("registering tools for %s: %w", name, err)
+	}
+	r.logger.Info("registered tools", "server", name, "count", count)
+
+	// Resources and prompts are optional: a server that fails to list them
+	// still has its tools served.
+	resources, err := r.registerResources(ctx, conn, pipes.response)
+	if err != nil {
+		r.logger.Warn("skipping resources", "server", name, "err", err)
+	} else if resources > 0 {
+		r.logger.Info("registered resources", "server", name, "count", resources)
+	}
+
+	prompts, err := r.registerPrompts(ctx, conn, pipes.response)
+	if err != nil {
+		r.logger.Warn("skipping prompts", "server", name, "err", err)
+	} else if prompts > 0 {
+		r.logger.Info("registered prompts", "server", name, "count", prompts)
+	}
+
+	return count, nil
+}
+
+// Watch keeps the upstream catalog in sync with the downstream servers.
+// When the downstream manager reports a change for a server that has already
+// been discovered its catalog is refreshed; a server that was pending at
+// startup and has just connected gets full discovery. With pinning on, a
+// server is also refreshed when another process, such as the approve
+// command, changes the lockfile entries of its tools. Blocks until ctx is
+// cancelled.
+func (r *Registry) Watch(ctx context.Context) {
+	// Approvals made with the approve command only change the lockfile, so
+	// it is polled for changes too.
+	var pinCheck <-chan time.Time
+	if r.pins != nil {
+		ticker := time.NewTicker(pinCheckInterval)
+		defer ticker.Stop()
+		pinCheck = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pinCheck:
+			r.reloadPins(ctx)
+		case <-r.downstream.ToolsChanged():
+			for _, name := range r.downstream.TakeChanged() {
+				if err := r.handleChange(ctx, name); err != nil {
+					r.logger.Error("re-discovery failed", "server", name, "err", err)
+				}
+			}
+		}
+	}
+}
+
+func (r *Registry) handleChange(ctx context.Context, serverName string) error {
+	if r.isDiscovered(serverName) {
+		return r.Refresh(ctx, serverName)
+	}
+
+	conn, ok := r.downstream.Conns()[serverName]
+	if !ok {
+		return fmt.Errorf("downstream %s not connected", serverName)
+	}
+
+	count, err := r.discoverServer(ctx, conn)
+	if err != nil {
+		return err
+	}
+	r.logger.Info("registered pending server", "server", serverName, "tools", count)
+	return nil
+}
+
+// isDiscovered reports whether the server's tools have been registered.
+func (r *Registry) isDiscovered(serverName string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tools[serverName]
+	return ok
+}
+
+// Refresh re-lists the tools, resources, resource templates and prompts of a
+// single downstream server and brings the upstream catalog in line: new and
+// changed entries are (re-)registered and those no longer offered are
+// removed. The upstream server notifies connected clients with the matching
+// list_changed notifications.
+func (r *Registry) Refresh(ctx context.Context, serverName string) error {
+	conn, ok := r.downstream.Conns()[serverName]
+	if !ok {
+		return fmt.Errorf("downstream %s not connected", serverName)
+	}
+
+	_, err := r.discoverServer(ctx, conn)
+	return err
+}
+
+// serverPipelines holds the sanitization pipelines for one server.
+type serverPipelines struct {
+	// cfg is the merged config the pipelines were built from.
+	cfg config.SanitizationConfig
+	// response scans everything returned to the LLM.
+	response *sanitizer.Pipeline
+	// arguments scans tool call arguments; nil when disabled.
+	arguments *sanitizer.Pipeline
+}
+
+// pipelines returns the cached sanitization pipelines for a server, building
+// them from the merged config on first use.
+func (r *Registry) pipelines(conn *transport.DownstreamConn) (*serverPipelines, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cachedPipelines(conn.Name, conn.Name, config.Merge(&r.globalCfg, conn.Config.Sanitization))
+}
+
+// toolPipelines returns the pipelines for one tool. Tools without a
+// sanitization override share the server's pipelines; the rest get their
+// own, built from the server config with the tool's override merged on top.
+func (r *Registry) toolPipelines(
+	conn *transport.DownstreamConn,
+	toolName string,
+	tc config.ToolConfig,
+) (*serverPipelines, error) {
+	if tc.Sanitization == nil {
+		return r.pipelines(conn)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	merged := config.Merge(&r.globalCfg, conn.Config.Sanitization)
+	merged = config.Merge(&merged, tc.Sanitization)
+	return r.cachedPipelines(conn.Name+namespaceSep+toolName, conn.Name, merged)
+}
+
+// cachedPipelines returns the pipelines cached under key, building them from
+// cfg on first use. Must be called with r.mu held.
+func (r *Registry) cachedPipelines(key, source string, cfg config.SanitizationConfig) (*serverPipelines, error) {
+	if p, ok := r.pipelineCache[key]; ok {
+		return p, nil
+	}
+
+	response, err := BuildPipeline(cfg, source)
+	if err != nil {
+		return nil, fmt.Errorf("building pipeline for %s: %w", key, err)
+	}
+	arguments, err := BuildArgumentPipeline(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("building argument pipeline for %s: %w", key, err)
+	}
+
+	observer := r.scanObserver(source)
+	response = response.WithObserver(observer)
+	if arguments != nil {
+		arguments = arguments.WithObserver(observer)
+	}
+
+	p := &serverPipelines{cfg: cfg, response: response, arguments: arguments}
+	r.pipelineCache[key] = p
+	return p, nil
+}
+
+// registerServer lists the server's tools, registers a proxy for each one
+// that passes the server's tool filters, and removes any previously
+// registered tools the server no longer offers (or that are now filtered).
+// Tool definitions are sanitized first, with the tool's own pipelines;
+// tools whose metadata is blocked are refused registration. Returns the
+// number of tools registered.
+func (r *Registry) registerServer(ctx context.Context, conn *transport.DownstreamConn) (int, error) {
+	serverName := conn.Name
+
+	var tools []*mcp.Tool
+	for tool, err := range conn.Session.Tools(ctx, nil) {
+		if err != nil {
+			return 0, fmt.Errorf("listing tools: %w", err)
+		}
+		if ok, reason := filterTool(conn.Config, tool); !ok {
+			r.logger.Debug("filtered tool", "server", serverName, "tool", tool.Name, "reason", reason)
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	toolCfgs := make([]config.ToolConfig, len(tools))
+	toolPipes := make([]*serverPipelines, len(tools))
+	for i, tool := range tools {
+		toolCfgs[i] = conn.Config.ResolveTool(tool.Name)
+		pipes, err := r.toolPipelines(conn, tool.Name, toolCfgs[i])
 		if err != nil {
-			return count, fmt.Errorf("listing tools: %w", err)
+			return 0, err
+		}
+		toolPipes[i] = pipes
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.tools[serverName]
+	current := make(map[string]*proxyTarget, len(tools))
+
+	for i, tool := range tools {
+		tc := toolCfgs[i]
+		upstreamName := upstreamToolName(conn.Config, tool.Name, tc)
+		pipes := toolPipes[i]
+		fields := pipes.response.Without("boundary")
+
+		// A name is taken if another server registered it, or another of
+		// this server's tools already claimed it in this pass.
+		owner := r.toolOwner(upstreamName)
+		if _, dup := current[upstreamName]; dup {
+			owner = serverName
+		} else if owner == serverName {
+			owner = ""
+		}
+		if owner != "" {
+			r.logger.Error("refused tool registration: name already registered",
+				"server", serverName,
+				"tool", tool.Name,
+				"name", upstreamName,
+				"owner", owner,
+			)
+			continue
 		}
 
-		namespacedName := serverName + namespaceSep + tool.Name
+		// Pins are keyed by the canonical server__tool name so that
+		// renaming a tool in config does not reset its pin.
+		if !r.checkPin(serverName, serverName+namespaceSep+tool.Name, tool) {
+			continue
+		}
+
+		clean, blocked, err := sanitizeToolDefinition(ctx, tool, fields)
+		if err != nil {
+			return 0, fmt.Errorf("sanitizing tool %s: %w", tool.Name, err)
+		}
+		if blocked != nil {
+			r.logger.Warn("refused tool registration",
+				"server", serverName,
+				"tool", tool.Name,
+				"threats", blocked.AllThreats,
+			)
+			continue
+		}
 
-		proxied := proxyTool(tool, namespacedName)
-		handler := proxyHandler(r.downstream, serverName, tool.Name, namespacedName, pipeline, r.logger)
+		if clean.InputSchema == nil || !isObjectSchema(clean.InputSchema) {
+			r.logger.Warn("refused tool registration: input schema is not type object",
+				"server", serverName,
+				"tool", tool.Name,
+			)
+			continue
+		}
+
+		if clean.OutputSchema != nil && !isObjectSchema(clean.OutputSchema) {
+			r.logger.Warn("dropping output schema without type object",
+				"server", serverName,
+				"tool", tool.Name,
+			)
+			clean.OutputSchema = nil
+		}
+
+		proxied, err := proxyTool(clean, upstreamName, tc)
+		if err != nil {
+			return 0, fmt.Errorf("proxying tool %s: %w", tool.Name, err)
+		}
+		target := &proxyTarget{
+			serverName:     serverName,
+			downstreamName: tool.Name,
+			upstreamName:   upstreamName,
+			cfg:            tc,
+			pipes:          pipes,
+		}
+		if !conn.Config.SkipArgumentValidation {
+			target.inputSchema = r.resolveSchema(serverName, tool.Name, "input", tool.InputSchema)
+		}
+		if mode := conn.Config.OutputValidation; clean.OutputSchema != nil && mode != "" && mode != config.OutputValidationOff {
+			target.outputSchema = r.resolveStrictSchema(serverName, tool.Name, tool.OutputSchema)
+			target.blockInvalidOutput = mode == config.OutputValidationBlock
+		}
+		handler := r.proxyHandler(target)
 		r.upstream.Server.AddTool(proxied, handler)
 
-		count++
+		current[upstreamName] = target
 	}
-	return count, nil
+
+	if removed := removedKeys(previous, current); len(removed) > 0 {
+		r.upstream.Server.RemoveTools(removed...)
+		r.logger.Info("removed tools", "server", serverName, "tools", removed)
+	}
+
+	r.tools[serverName] = current
+
+	if r.pins != nil {
+		if err := r.pins.Save(); err != nil {
+			r.logger.Error("saving lockfile", "path", r.pins.Path(), "err", err)
+		}
+	}
+
+	return len(current), nil
 }
 
-// proxyTool creates a copy of the downstream tool with a namespaced name.
-func proxyTool(original *mcp.Tool, namespacedName string) *mcp.Tool {
+// removedKeys returns the keys of previous that are not in current.
+func removedKeys[V any](previous, current map[string]V) []string {
+	var removed []string
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+// toolOwner returns the server that has registered the upstream tool name,
+// or "" if none has. Must be called with r.mu held.
+func (r *Registry) toolOwner(upstreamName string) string {
+	for server, names := range r.tools {
+		if _, ok := names[upstreamName]; ok {
+			return server
+		}
+	}
+	return ""
+}
+
+// upstreamToolName returns the name a downstream tool is exposed as:
+// the configured rename or the original name, behind the server's prefix
+// and separator.
+func upstreamToolName(cfg config.DownstreamConfig, toolName string, tc config.ToolConfig) string {
+	if tc.Name != "" {
+		return prefixedName(cfg, tc.Name)
+	}
+	return prefixedName(cfg, toolName)
+}
+
+// prefixedName returns name behind the server's prefix and separator, as
+// its tools, prompts and resources are named upstream. An empty prefix
+// exposes the bare name.
+func prefixedName(cfg config.DownstreamConfig, name string) string {
+	prefix := cfg.Name
+	if cfg.Prefix != nil {
+		prefix = *cfg.Prefix
+	}
+	if prefix == "" {
+		return name
+	}
+
+	sep := cfg.Separator
+	if sep == "" {
+		sep = namespaceSep
+	}
+	return prefix + sep + name
+}
+
+// pinCheckInterval is how often Watch checks the lockfile for changes made
+// by other processes.
+const pinCheckInterval = 5 * time.Second
+
+// reloadPins re-reads the lockfile if another process has saved it and
+// refreshes the discovered servers whose tool entries it changed, so that
+// newly approved tools are registered.
+func (r *Registry) reloadPins(ctx context.Context) {
+	var servers []string
+	for _, name := range r.pins.Reload() {
+		server, _, _ := strings.Cut(name, namespaceSep)
+		if !slices.Contains(servers, server) && r.isDiscovered(server) {
+			servers = append(servers, server)
+		}
+	}
+
+	for _, server := range servers {
+		r.logger.Info("lockfile changed, refreshing tools", "server", server)
+		if err := r.Refresh(ctx, server); err != nil {
+			r.logger.Error("re-discovery failed", "server", server, "err", err)
+		}
+	}
+}
+
+// checkPin compares the tool's fingerprint with the lockfile and reports
+// whether the tool may be registered. Changed definitions are always logged;
+// under the block policy they are also refused until approved.
+func (r *Registry) checkPin(serverName, namespacedName string, tool *mcp.Tool) bool {
+	if r.pins == nil {
+		return true
+	}
+
+	fp, err := pinning.Fingerprint(tool)
+	if err != nil {
+		r.logger.Error("refused tool registration", "server", serverName, "tool", tool.Name, "err", err)
+		return false
+	}
+
+	switch r.pins.Check(namespacedName, fp) {
+	case pinning.StatusNew:
+		r.logger.Info("pinned new tool", "server", serverName, "tool", tool.Name)
+	case pinning.StatusChanged:
+		if r.pinPolicy == config.PinningBlock {
+			r.logger.Warn("tool definition changed, refusing registration until approved",
+				"server", serverName,
+				"tool", tool.Name,
+			)
+			return false
+		}
+		r.logger.Warn("tool definition changed since it was pinned",
+			"server", serverName,
+			"tool", tool.Name,
+		)
+	}
+	return true
+}
+
+// proxyTool creates a copy of the downstream tool with its upstream name,
+// any description override from config applied, and the input schema
+// rewritten for fixed and default arguments.
+func proxyTool(original *mcp.Tool, upstreamName string, tc config.ToolConfig) (*mcp.Tool, error) {
+	description := original.Description
+	if tc.Description != "" {
+		description = tc.Description
+	}
+	if tc.DescriptionAppend != "" {
+		description = strings.TrimSpace(description + "\n\n" + tc.DescriptionAppend)
+	}
+
+	schema, err := rewriteInputSchema(original.InputSchema, tc)
+	if err != nil {
+		return nil, err
+	}
+
 	return &mcp.Tool{
-		Name:        namespacedName,
-		Description: original.Description,
-		InputSchema: original.InputSchema,
-		Annotations: original.Annotations,
-		Title:       original.Title,
+		Name:         upstreamName,
+		Description:  description,
+		InputSchema:  schema,
+		OutputSchema: original.OutputSchema,
+		Annotations:  original.Annotations,
+		Title:        original.Title,
+	}, nil
+}
+
+// proxyTarget describes the downstream tool behind an upstream proxy.
+type proxyTarget struct {
+	serverName     string
+	downstreamName string
+	upstreamName   string
+	cfg            config.ToolConfig
+	pipes          *serverPipelines
+	// inputSchema validates the arguments sent downstream; nil when
+	// validation is skipped.
+	inputSchema *jsonschema.Resolved
+	// outputSchema validates structured results; nil when output
+	// validation is off or the tool has no output schema.
+	outputSchema       *jsonschema.Resolved
+	blockInvalidOutput bool
+}
+
+// proxyHandler returns a ToolHandler that forwards the calls the access
+// policy allows to the target with forwardCall, tracing each call and
+// recording it, allowed or denied, in the metrics, the tool's stats and the
+// audit log. The trace continues any W3C trace context sent in the
+// request's _meta.
+func (r *Registry) proxyHandler(target *proxyTarget) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		principal := auth.PrincipalFrom(ctx)
+		logger := r.logger.With(
+			"server", target.serverName,
+			"tool", target.upstreamName,
+			"session", sessionID(req),
+		)
+		if principal != nil {
+			logger = logger.With("principal", principal.String())
+		}
+
+		ctx = tracing.Extract(ctx, req.Params.Meta)
+		ctx, span := r.tracer.Start(ctx, "tools/call "+target.upstreamName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("mcp.method.name", "tools/call"),
+				attribute.String("gen_ai.tool.name", target.upstreamName),
+				attribute.String("gateway.downstream.server", target.serverName),
+			),
+		)
+		defer span.End()
+		if principal != nil {
+			span.SetAttributes(attribute.String("enduser.id", principal.Subject))
+		}
+
+		ctx, rec := withCallRecord(ctx)
+		var (
+			result  *mcp.CallToolResult
+			outcome string
+			err     error
+		)
+		if r.access.Allows(principal, target.serverName, target.downstreamName) {
+			result, outcome, err = forwardCall(ctx, r.downstream, target, req, logger)
+		} else {
+			logger.Warn("denied tool call")
+			result, outcome = deniedResult(target.upstreamName), metrics.OutcomeDenied
+		}
+
+		r.metrics.ToolCall(target.serverName, target.downstreamName, outcome)
+		if rec.downstreamLatency > 0 {
+			r.metrics.DownstreamCall(target.serverName, target.downstreamName, rec.downstreamLatency)
+		}
+		r.recordStats(target, rec, outcome, start)
+		if werr := r.audit.Write(auditRecord(start, req, principal, target, rec, outcome, err)); werr != nil {
+			logger.Error("writing audit record", "err", werr)
+		}
+
+		span.SetAttributes(attribute.String("gateway.outcome", outcome))
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case outcome != metrics.OutcomeSuccess:
+			span.SetStatus(codes.Error, outcome)
+		}
+		return result, err
 	}
 }
 
-// proxyHandler returns a ToolHandler that forwards calls to the downstream
-// session, then sanitizes the response. It looks up the session at call time
-// so that reconnected sessions are used automatically.
-func proxyHandler(
+// forwardCall scans the call arguments, applies the tool's fixed and default
+// arguments, validates them against the downstream input schema, forwards
+// the call to the downstream session, checks structured results against the
+// output schema, then sanitizes the response. It looks up the session at
+// call time so that reconnected sessions are used automatically. Along with
+// the result it returns the call's outcome, one of the metrics.Outcome
+// constants. The downstream latency and any output schema violations are
+// added to the call record in ctx.
+func forwardCall(
+	ctx context.Context,
 	dm *transport.DownstreamManager,
-	serverName string,
-	downstreamName string,
-	namespacedName string,
-	pipeline *sanitizer.Pipeline,
+	target *proxyTarget,
+	req *mcp.CallToolRequest,
 	logger *slog.Logger,
-) mcp.ToolHandler {
-	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		session := dm.Session(serverName)
-		if session == nil {
-			return nil, fmt.Errorf("downstream %s not connected", serverName)
-		}
+) (*mcp.CallToolResult, string, error) {
+	pipes := target.pipes
 
-		// Forward to downstream with original tool name.
-		result, err := session.CallTool(ctx, &mcp.CallToolParams{
-			Name:      downstreamName,
-			Arguments: req.Params.Arguments,
-		})
+	if pipes.arguments != nil {
+		blocked, err := scanArguments(ctx, req.Params.Arguments, pipes.arguments)
 		if err != nil {
-			return nil, fmt.Errorf("downstream call %s: %w", namespacedName, err)
+			return nil, metrics.OutcomeError, fmt.Errorf("scanning arguments for %s: %w", target.upstreamName, err)
+		}
+		if blocked != nil {
+			logger.Warn("blocked tool call arguments", "threats", blocked.AllThreats)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "tool call blocked: " + blockReason(*blocked)}},
+				IsError: true,
+			}, metrics.OutcomeBlocked, nil
 		}
+	}
 
-		// Sanitize each text content item.
-		return sanitizeResult(ctx, result, pipeline, logger)
+	// Configured arguments are applied after scanning: they come from
+	// config, not the LLM.
+	args, err := applyArguments(req.Params.Arguments, target.cfg)
+	if err != nil {
+		return invalidArgumentsResult(target.upstreamName, err), metrics.OutcomeInvalid, nil
+	}
+
+	if target.inputSchema != nil {
+		if err := validateArguments(target.inputSchema, args); err != nil {
+			logger.Debug("rejected invalid tool call arguments", "err", err)
+			return invalidArgumentsResult(target.upstreamName, err), metrics.OutcomeInvalid, nil
+		}
+	}
+
+	session := dm.Session(target.serverName)
+	if session == nil {
+		return nil, metrics.OutcomeError, fmt.Errorf("downstream %s not connected", target.serverName)
+	}
+
+	// Forward to downstream with original tool name.
+	start := time.Now()
+	result, err := callDownstream(ctx, session, target, args)
+	callRecordFrom(ctx).setDownstreamLatency(time.Since(start))
+	if err != nil {
+		return nil, metrics.OutcomeError, fmt.Errorf("downstream call %s: %w", target.upstreamName, err)
+	}
+
+	if target.outputSchema != nil && !result.IsError {
+		report := pipes.cfg.Mode == config.ModeReport
+		if pr := checkStructuredOutput(target.outputSchema, result, target.blockInvalidOutput, report); pr != nil {
+			callRecordFrom(ctx).addScans(pr.ScanResults...)
+			if pr.FinalVerdict == sanitizer.VerdictBlock {
+				return blockedResult(*pr, logger), metrics.OutcomeBlocked, nil
+			}
+			logger.Warn("structured content violates output schema", "threats", pr.AllThreats)
+		}
+	}
+
+	downstreamError := result.IsError
+
+	// Sanitize each text content item.
+	result, err = sanitizeResult(ctx, target.serverName, result, pipes.response, logger)
+	switch {
+	case err != nil:
+		return nil, metrics.OutcomeError, err
+	case downstreamError:
+		return result, metrics.OutcomeError, nil
+	case result.IsError:
+		return result, metrics.OutcomeBlocked, nil
+	default:
+		return result, metrics.OutcomeSuccess, nil
 	}
 }
 
-// sanitizeResult runs each TextContent through the pipeline.
+// callDownstream forwards a call to the downstream session inside a client
+// span, propagating the trace context in the request's _meta.
+func callDownstream(
+	ctx context.Context,
+	session *mcp.ClientSession,
+	target *proxyTarget,
+	args json.RawMessage,
+) (*mcp.CallToolResult, error) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracing.ScopeName)
+	ctx, span := tracer.Start(ctx, "tools/call "+target.downstreamName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("mcp.method.name", "tools/call"),
+			attribute.String("gen_ai.tool.name", target.downstreamName),
+			attribute.String("gateway.downstream.server", target.serverName),
+		),
+	)
+	defer span.End()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      tracing.Inject(ctx, nil),
+		Name:      target.downstreamName,
+		Arguments: args,
+	})
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case result.IsError:
+		span.SetStatus(codes.Error, "tool returned an error")
+	}
+	return result, err
+}
+
+// invalidArgumentsResult is the error result returned when a call's
+// arguments cannot be decoded or do not match the tool's input schema. The
+// message is meant for the LLM to correct its call.
+func invalidArgumentsResult(toolName string, err error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments for tool %s: %v", toolName, err)}},
+		IsError: true,
+	}
+}
+
+// sanitizeResult runs every piece of text in a tool result through the
+// pipeline: text content, embedded text resources, resource link titles and
+// descriptions, and each string leaf of the structured content. Link
+// metadata and structured fields skip the boundary scanner so they remain
+// usable as data. Resource URIs are namespaced under serverName.
 // On Block: replaces entire result with an IsError response.
-// On Modify: replaces text content with sanitized version.
+// On Modify: replaces the affected text with the sanitized version.
 func sanitizeResult(
 	ctx context.Context,
+	serverName string,
 	result *mcp.CallToolResult,
 	pipeline *sanitizer.Pipeline,
 	logger *slog.Logger,
 ) (*mcp.CallToolResult, error) {
+	fields := pipeline.Without("boundary")
+
 	for i, content := range result.Content {
-		tc, ok := content.(*mcp.TextContent)
-		if !ok {
-			continue
+		clean, blocked, err := sanitizeContent(ctx, serverName, content, pipeline, fields)
+		if err != nil {
+			return nil, err
+		}
+		if blocked != nil {
+			return blockedResult(*blocked, logger), nil
 		}
+		result.Content[i] = clean
+	}
 
-		pr, err := pipeline.Process(ctx, tc.Text)
+	if result.StructuredContent != nil {
+		structured, blocked, err := sanitizeStructured(ctx, fields, result.StructuredContent)
 		if err != nil {
 			return nil, err
 		}
+		if blocked != nil {
+			return blockedResult(*blocked, logger), nil
+		}
+		result.StructuredContent = structured
+	}
 
-		switch pr.FinalVerdict {
-		case sanitizer.VerdictBlock:
-			reason := "blocked by sanitization"
-			if len(pr.AllThreats) > 0 {
-				reason = strings.Join(pr.AllThreats, "; ")
-			}
-			logger.Warn("blocked tool response",
-				"threats", pr.AllThreats,
-			)
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: reason}},
-				IsError: true,
-			}, nil
+	return result, nil
+}
 
-		case sanitizer.VerdictModify:
-			result.Content[i] = &mcp.TextContent{
-				Text:        pr.FinalContent,
-				Annotations: tc.Annotations,
+// sanitizeContent runs the text of one content item through the pipeline:
+// the text of text content and embedded text resources, and the title and
+// description of resource links, which are scanned with fields, a pipeline
+// without the boundary scanner. The URIs of embedded resources and resource
+// links are rewritten into the gateway namespace of serverName, so that
+// they can be read back through the gateway. It returns the content to use
+// in its place, a rebuilt copy when anything changed, or the blocking
+// result.
+func sanitizeContent(
+	ctx context.Context,
+	serverName string,
+	content mcp.Content,
+	pipeline *sanitizer.Pipeline,
+	fields *sanitizer.Pipeline,
+) (mcp.Content, *sanitizer.PipelineResult, error) {
+	switch c := content.(type) {
+	case *mcp.TextContent:
+		text, blocked, err := sanitizeString(ctx, pipeline, c.Text)
+		if err != nil || blocked != nil {
+			return nil, blocked, err
+		}
+		if text != c.Text {
+			return &mcp.TextContent{
+				Text:        text,
+				Meta:        c.Meta,
+				Annotations: c.Annotations,
+			}, nil, nil
+		}
+
+	case *mcp.EmbeddedResource:
+		if c.Resource == nil {
+			return c, nil, nil
+		}
+		rc := *c.Resource
+		rc.URI = namespaceURI(serverName, rc.URI)
+		if rc.Blob == nil {
+			text, blocked, err := sanitizeString(ctx, pipeline, rc.Text)
+			if err != nil || blocked != nil {
+				return nil, blocked, err
 			}
+			rc.Text = text
 		}
+		return &mcp.EmbeddedResource{
+			Resource:    &rc,
+			Meta:        c.Meta,
+			Annotations: c.Annotations,
+		}, nil, nil
+
+	case *mcp.ResourceLink:
+		link := *c
+		link.URI = namespaceURI(serverName, c.URI)
+		blocked, err := sanitizeFields(ctx, fields, &link.Title, &link.Description)
+		if err != nil || blocked != nil {
+			return nil, blocked, err
+		}
+		return &link, nil, nil
 	}
 
-	return result, nil
+	return content, nil, nil
+}
+
+// sanitizeString runs s through the pipeline. It returns the text to use in
+// place of s and, if the pipeline blocked it, the blocking result. Empty
+// strings are returned as-is without scanning.
+func sanitizeString(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	s string,
+) (string, *sanitizer.PipelineResult, error) {
+	if s == "" {
+		return s, nil, nil
+	}
+
+	pr, err := pipeline.Process(ctx, s)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch pr.FinalVerdict {
+	case sanitizer.VerdictBlock:
+		return "", &pr, nil
+	case sanitizer.VerdictModify:
+		return pr.FinalContent, nil, nil
+	default:
+		return s, nil, nil
+	}
+}
+
+// blockedResult logs a blocked tool response and returns the IsError result
+// sent upstream in its place.
+func blockedResult(pr sanitizer.PipelineResult, logger *slog.Logger) *mcp.CallToolResult {
+	logger.Warn("blocked tool response",
+		"threats", pr.AllThreats,
+	)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: blockReason(pr)}},
+		IsError: true,
+	}
+}
+
+// blockReason describes why the pipeline blocked content.
+func blockReason(pr sanitizer.PipelineResult) string {
+	if len(pr.AllThreats) == 0 {
+		return "blocked by sanitization"
+	}
+	return strings.Join(pr.AllThreats, "; ")
 }
 
 // BuildPipeline constructs a sanitizer.Pipeline from a (merged) config.
-// Scanner order: unicode -> length -> injection -> override -> url -> boundary.
+// Scanner order: unicode -> secrets -> pii -> length -> injection -> override -> url -> boundary.
+// Secrets and PII run before length so that truncation cannot cut a value
+// in half and leave a fragment the rules no longer recognise.
 func BuildPipeline(cfg config.SanitizationConfig, source string) (*sanitizer.Pipeline, error) {
 	var scanners []sanitizer.Scanner
 
@@ -189,6 +962,22 @@ func BuildPipeline(cfg config.SanitizationConfig, source string) (*sanitizer.Pip
 		scanners = append(scanners, &sanitizer.UnicodeScanner{})
 	}
 
+	if deref(cfg.EnableSecretRedaction) {
+		s, err := buildSecretsScanner(cfg)
+		if err != nil {
+			return nil, err
+		}
+		scanners = append(scanners, s)
+	}
+
+	if cfg.PII != nil && deref(cfg.PII.Enabled) {
+		s, err := buildPIIScanner(cfg.PII)
+		if err != nil {
+			return nil, err
+		}
+		scanners = append(scanners, s)
+	}
+
 	if cfg.MaxResponseChars != nil && *cfg.MaxResponseChars > 0 {
 		scanners = append(scanners, sanitizer.NewLengthScanner(*cfg.MaxResponseChars))
 	}
@@ -216,7 +1005,117 @@ func BuildPipeline(cfg config.SanitizationConfig, source string) (*sanitizer.Pip
 		scanners = append(scanners, sanitizer.NewBoundaryScanner(source))
 	}
 
-	return sanitizer.NewPipeline(scanners...), nil
+	return newPipeline(cfg, scanners), nil
+}
+
+// BuildArgumentPipeline constructs the outbound pipeline that scans tool call
+// arguments. Returns nil if argument scanning is disabled.
+// Scanner order: secrets -> injection -> override -> url. Unlike the
+// response pipeline, a secret in the arguments blocks the call.
+func BuildArgumentPipeline(cfg config.SanitizationConfig) (*sanitizer.Pipeline, error) {
+	args := cfg.Arguments
+	if args == nil || !deref(args.Enabled) {
+		return nil, nil
+	}
+
+	var scanners []sanitizer.Scanner
+
+	if deref(args.EnableSecretDetection) {
+		s, err := buildSecretsScanner(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.Block = true
+		scanners = append(scanners, s)
+	}
+
+	if deref(args.EnablePromptInjectionDetection) {
+		s, err := sanitizer.NewInjectionScanner(
+			deref(cfg.DisableBuiltInPatterns),
+			cfg.CustomInjectionPatterns,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("injection scanner: %w", err)
+		}
+		scanners = append(scanners, s)
+	}
+
+	if deref(args.EnableSystemOverrideDetection) {
+		scanners = append(scanners, &sanitizer.OverrideScanner{})
+	}
+
+	if deref(args.EnableURLValidation) {
+		scanners = append(scanners, &sanitizer.URLScanner{})
+	}
+
+	return newPipeline(cfg, scanners), nil
+}
+
+// newPipeline creates a pipeline from scanners, putting those configured
+// for report mode in report mode.
+func newPipeline(cfg config.SanitizationConfig, scanners []sanitizer.Scanner) *sanitizer.Pipeline {
+	var report []string
+	for _, s := range scanners {
+		if cfg.ScannerMode(s.Name()) == config.ModeReport {
+			report = append(report, s.Name())
+		}
+	}
+	p := sanitizer.NewPipeline(scanners...)
+	if len(report) > 0 {
+		p = p.ReportOnly(report...)
+	}
+	return p
+}
+
+func buildSecretsScanner(cfg config.SanitizationConfig) (*sanitizer.SecretsScanner, error) {
+	rules := make([]sanitizer.SecretRule, 0, len(cfg.CustomSecretRules))
+	for _, r := range cfg.CustomSecretRules {
+		rules = append(rules, sanitizer.SecretRule{
+			Name:       r.Name,
+			Pattern:    r.Pattern,
+			MinEntropy: r.MinEntropy,
+		})
+	}
+
+	s, err := sanitizer.NewSecretsScanner(deref(cfg.DisableBuiltInSecretRules), rules)
+	if err != nil {
+		return nil, fmt.Errorf("secrets scanner: %w", err)
+	}
+	return s, nil
+}
+
+func buildPIIScanner(cfg *config.PIIConfig) (*sanitizer.PIIScanner, error) {
+	var entities []sanitizer.PIIEntity
+	for _, e := range []struct {
+		enabled *bool
+		entity  sanitizer.PIIEntity
+	}{
+		{cfg.Email, sanitizer.PIIEmail},
+		{cfg.Phone, sanitizer.PIIPhone},
+		{cfg.IBAN, sanitizer.PIIIBAN},
+		{cfg.CreditCard, sanitizer.PIICreditCard},
+		{cfg.NationalID, sanitizer.PIINationalID},
+	} {
+		if deref(e.enabled) {
+			entities = append(entities, e.entity)
+		}
+	}
+
+	var action sanitizer.PIIAction
+	switch cfg.Action {
+	case config.PIIActionHash:
+		action = sanitizer.PIIHash
+	case config.PIIActionBlock:
+		action = sanitizer.PIIBlock
+	default:
+		action = sanitizer.PIIRedact
+	}
+
+	s, err := sanitizer.NewPIIScanner(entities, action, []byte(cfg.HashKey))
+	if err != nil {
+		return nil, fmt.Errorf("pii scanner: %w", err)
+	}
+	return s, nil
 }
 
 func deref(b *bool) bool {
@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resourceScheme is the URI scheme used for namespaced resources. A
+// downstream resource "file:///a.txt" on server "fs" is exposed upstream as
+// "mcpgw://fs/file:///a.txt". Prefixing with "fs__" directly is not possible
+// because the result must still parse as an absolute URI.
+const resourceScheme = "mcpgw"
+
+// namespaceURI prefixes a downstream resource URI (or URI template) with the
+// gateway scheme and server name.
+func namespaceURI(serverName, uri string) string {
+	return resourceScheme + "://" + serverName + "/" + uri
+}
+
+// stripURINamespace reverses namespaceURI. Returns false if uri does not
+// belong to serverName.
+func stripURINamespace(serverName, uri string) (string, bool) {
+	return strings.CutPrefix(uri, resourceScheme+"://"+serverName+"/")
+}
+
+// registerResources discovers resources and resource templates from a
+// downstream session, registers namespaced proxies on the upstream server,
+// and removes any previously registered ones the server no longer offers.
+// Returns the number of resources and templates registered.
+func (r *Registry) registerResources(
+	ctx context.Context,
+	conn *transport.DownstreamConn,
+	pipeline *sanitizer.Pipeline,
+) (int, error) {
+	serverName := conn.Name
+	resources, templates, err := r.addResources(ctx, conn, pipeline)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if removed := removedKeys(r.resources[serverName], resources); len(removed) > 0 {
+		r.upstream.Server.RemoveResources(removed...)
+		r.logger.Info("removed resources", "server", serverName, "uris", removed)
+	}
+	if removed := removedKeys(r.templates[serverName], templates); len(removed) > 0 {
+		r.upstream.Server.RemoveResourceTemplates(removed...)
+		r.logger.Info("removed resource templates", "server", serverName, "templates", removed)
+	}
+	r.resources[serverName] = resources
+	r.templates[serverName] = templates
+
+	return len(resources) + len(templates), nil
+}
+
+// addResources registers a proxy for each resource and resource template of
+// the downstream server, named behind the server's prefix and separator as
+// its tools are. Titles and descriptions are sanitized first;
+// resources whose metadata is blocked are refused registration. It returns
+// the upstream URIs and URI templates registered, each mapped to its
+// downstream name. Servers that do not advertise the resources capability
+// have none.
+func (r *Registry) addResources(
+	ctx context.Context,
+	conn *transport.DownstreamConn,
+	pipeline *sanitizer.Pipeline,
+) (map[string]string, map[string]string, error) {
+	serverName := conn.Name
+	session := conn.Session
+	resources := make(map[string]string)
+	templates := make(map[string]string)
+	if !hasResources(session) {
+		return resources, templates, nil
+	}
+
+	handler := proxyResourceHandler(r.downstream, serverName, pipeline, r.logger)
+	fields := pipeline.Without("boundary")
+
+	for res, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing resources: %w", err)
+		}
+		proxied := proxyResource(res, conn.Config)
+		blocked, err := sanitizeFields(ctx, fields, &proxied.Title, &proxied.Description)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sanitizing resource %s: %w", res.Name, err)
+		}
+		if blocked != nil {
+			r.logger.Warn("refused resource registration",
+				"server", serverName,
+				"resource", res.Name,
+				"threats", blocked.AllThreats,
+			)
+			continue
+		}
+		r.upstream.Server.AddResource(proxied, r.guardResource(serverName, res.Name, handler))
+		resources[proxied.URI] = res.Name
+	}
+
+	for tmpl, err := range session.ResourceTemplates(ctx, nil) {
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing resource templates: %w", err)
+		}
+		proxied := proxyResourceTemplate(tmpl, conn.Config)
+		blocked, err := sanitizeFields(ctx, fields, &proxied.Title, &proxied.Description)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sanitizing resource template %s: %w", tmpl.Name, err)
+		}
+		if blocked != nil {
+			r.logger.Warn("refused resource template registration",
+				"server", serverName,
+				"template", tmpl.Name,
+				"threats", blocked.AllThreats,
+			)
+			continue
+		}
+		r.upstream.Server.AddResourceTemplate(proxied, r.guardResource(serverName, tmpl.Name, handler))
+		templates[proxied.URITemplate] = tmpl.Name
+	}
+
+	return resources, templates, nil
+}
+
+func hasResources(session *mcp.ClientSession) bool {
+	init := session.InitializeResult()
+	return init != nil && init.Capabilities != nil && init.Capabilities.Resources != nil
+}
+
+// proxyResource creates a copy of the downstream resource with a namespaced
+// name and URI.
+func proxyResource(original *mcp.Resource, cfg config.DownstreamConfig) *mcp.Resource {
+	return &mcp.Resource{
+		Name:        prefixedName(cfg, original.Name),
+		URI:         namespaceURI(cfg.Name, original.URI),
+		Title:       original.Title,
+		Description: original.Description,
+		MIMEType:    original.MIMEType,
+		Size:        original.Size,
+		Annotations: original.Annotations,
+	}
+}
+
+// proxyResourceTemplate creates a copy of the downstream template with a
+// namespaced name and URI template.
+func proxyResourceTemplate(original *mcp.ResourceTemplate, cfg config.DownstreamConfig) *mcp.ResourceTemplate {
+	return &mcp.ResourceTemplate{
+		Name:        prefixedName(cfg, original.Name),
+		URITemplate: namespaceURI(cfg.Name, original.URITemplate),
+		Title:       original.Title,
+		Description: original.Description,
+		MIMEType:    original.MIMEType,
+		Annotations: original.Annotations,
+	}
+}
+
+// proxyResourceHandler returns a ResourceHandler that strips the namespace
+// from the requested URI, reads it from the downstream session and sanitizes
+// the returned text contents. The same handler serves both concrete
+// resources and templates.
+func proxyResourceHandler(
+	dm *transport.DownstreamManager,
+	serverName string,
+	pipeline *sanitizer.Pipeline,
+	logger *slog.Logger,
+) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri, ok := stripURINamespace(serverName, req.Params.URI)
+		if !ok {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
+		session := dm.Session(serverName)
+		if session == nil {
+			return nil, fmt.Errorf("downstream %s not connected", serverName)
+		}
+
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			return nil, fmt.Errorf("downstream read %s: %w", req.Params.URI, err)
+		}
+
+		return sanitizeResourceResult(ctx, serverName, result, pipeline, logger)
+	}
+}
+
+// sanitizeResourceResult runs each text resource content through the
+// pipeline and rewrites content URIs into the gateway namespace. Binary
+// blobs are passed through unchanged.
+// On Block: the whole read fails with an error describing the threats.
+// On Modify: the text is replaced with the sanitized version.
+func sanitizeResourceResult(
+	ctx context.Context,
+	serverName string,
+	result *mcp.ReadResourceResult,
+	pipeline *sanitizer.Pipeline,
+	logger *slog.Logger,
+) (*mcp.ReadResourceResult, error) {
+	contents := make([]*mcp.ResourceContents, 0, len(result.Contents))
+
+	for _, rc := range result.Contents {
+		out := *rc
+		out.URI = namespaceURI(serverName, rc.URI)
+
+		if rc.Blob == nil {
+			pr, err := pipeline.Process(ctx, rc.Text)
+			if err != nil {
+				return nil, err
+			}
+
+			switch pr.FinalVerdict {
+			case sanitizer.VerdictBlock:
+				logger.Warn("blocked resource read",
+					"uri", out.URI,
+					"threats", pr.AllThreats,
+				)
+				return nil, fmt.Errorf("resource %s: %s", out.URI, blockReason(pr))
+
+			case sanitizer.VerdictModify:
+				out.Text = pr.FinalContent
+			}
+		}
+
+		contents = append(contents, &out)
+	}
+
+	return &mcp.ReadResourceResult{Meta: result.Meta, Contents: contents}, nil
+}
@@ -0,0 +1,202 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resolveSchema prepares a downstream JSON schema for validation. A schema
+// that cannot be resolved, for example because it uses remote references
+// or an unsupported draft, is logged and nil is returned so that the tool
+// is still registered, just without validation.
+func (r *Registry) resolveSchema(serverName, toolName, kind string, schema any) *jsonschema.Resolved {
+	if schema == nil {
+		return nil
+	}
+
+	resolved, err := compileSchema(schema)
+	if err != nil {
+		r.logger.Warn("skipping schema validation",
+			"server", serverName,
+			"tool", toolName,
+			"schema", kind,
+			"err", err,
+		)
+		return nil
+	}
+	return resolved
+}
+
+// resolveStrictSchema is resolveSchema for output schemas, which are made
+// strict first so that undeclared properties fail validation.
+func (r *Registry) resolveStrictSchema(serverName, toolName string, schema any) *jsonschema.Resolved {
+	strict, err := strictSchema(schema)
+	if err != nil {
+		r.logger.Warn("skipping schema validation",
+			"server", serverName,
+			"tool", toolName,
+			"schema", "output",
+			"err", err,
+		)
+		return nil
+	}
+	return r.resolveSchema(serverName, toolName, "output", strict)
+}
+
+// compileSchema converts a schema in any JSON-compatible form into a
+// resolved jsonschema.Schema.
+func compileSchema(schema any) (*jsonschema.Resolved, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("encoding schema: %w", err)
+	}
+
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decoding schema: %w", err)
+	}
+
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("resolving schema: %w", err)
+	}
+	return resolved, nil
+}
+
+// validateArguments checks raw tool call arguments against the tool's input
+// schema. Missing arguments are validated as an empty object.
+func validateArguments(schema *jsonschema.Resolved, raw json.RawMessage) error {
+	var args any = map[string]any{}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return fmt.Errorf("decoding arguments: %w", err)
+		}
+	}
+	return schema.Validate(args)
+}
+
+// isObjectSchema reports whether schema declares "type": "object", which
+// the upstream server requires of output schemas.
+func isObjectSchema(schema any) bool {
+	v, err := normalizeJSON(schema)
+	if err != nil {
+		return false
+	}
+	obj, ok := v.(map[string]any)
+	return ok && obj["type"] == "object"
+}
+
+// strictSchema returns a copy of a JSON schema in which every object schema
+// that declares properties or combines subschemas, but says nothing about
+// additional properties, rejects properties it does not evaluate, so that a
+// server cannot add undeclared fields to its output.
+//
+// It uses unevaluatedProperties rather than additionalProperties so that
+// properties declared in allOf, anyOf and oneOf branches count as declared.
+// Subschemas under composition keywords, and $defs they may reference, are
+// left as they are: each branch sees the whole instance, so tightening a
+// branch would reject the properties of its siblings.
+func strictSchema(schema any) (any, error) {
+	v, err := normalizeJSON(schema)
+	if err != nil {
+		return nil, err
+	}
+	return strictValue(v), nil
+}
+
+func strictValue(v any) any {
+	s, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+
+	out := make(map[string]any, len(s)+1)
+	for k, val := range s {
+		switch k {
+		case "properties", "patternProperties":
+			if m, ok := val.(map[string]any); ok {
+				sub := make(map[string]any, len(m))
+				for name, schema := range m {
+					sub[name] = strictValue(schema)
+				}
+				val = sub
+			}
+		case "prefixItems":
+			if list, ok := val.([]any); ok {
+				sub := make([]any, len(list))
+				for i, schema := range list {
+					sub[i] = strictValue(schema)
+				}
+				val = sub
+			}
+		case "items", "additionalProperties":
+			val = strictValue(val)
+		}
+		out[k] = val
+	}
+
+	declares := false
+	for _, k := range []string{"properties", "allOf", "anyOf", "oneOf"} {
+		if _, ok := s[k]; ok {
+			declares = true
+		}
+	}
+	_, hasAdditional := s["additionalProperties"]
+	_, hasPattern := s["patternProperties"]
+	_, hasUnevaluated := s["unevaluatedProperties"]
+	if declares && !hasAdditional && !hasPattern && !hasUnevaluated {
+		out["unevaluatedProperties"] = false
+	}
+	return out
+}
+
+// checkStructuredOutput validates a tool result's structured content
+// against the tool's output schema and returns a PipelineResult describing
+// the violation, or nil if the content conforms. In block mode the result's
+// verdict is VerdictBlock; otherwise the violation is only warned about.
+// With report set, as for a server in report mode, a block is downgraded
+// the way the pipeline downgrades a reported scanner: the scan result keeps
+// its verdict and is marked Reported, but the final verdict passes.
+func checkStructuredOutput(
+	schema *jsonschema.Resolved,
+	result *mcp.CallToolResult,
+	block, report bool,
+) *sanitizer.PipelineResult {
+	var err error
+	if result.StructuredContent == nil {
+		err = errors.New("missing structured content")
+	} else {
+		var v any
+		if v, err = normalizeJSON(result.StructuredContent); err == nil {
+			err = schema.Validate(v)
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	verdict := sanitizer.VerdictPass
+	if block {
+		verdict = sanitizer.VerdictBlock
+	}
+	threats := []string{fmt.Sprintf("structured content violates output schema: %v", err)}
+	pr := &sanitizer.PipelineResult{
+		FinalVerdict: verdict,
+		AllThreats:   threats,
+		ScanResults: []sanitizer.ScanResult{{
+			Verdict:     verdict,
+			Threats:     threats,
+			ScannerName: "output_schema",
+			Reported:    report,
+		}},
+	}
+	if report {
+		pr.FinalVerdict = sanitizer.VerdictPass
+	}
+	return pr
+}
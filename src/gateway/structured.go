@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+)
+
+// sanitizeStructured walks a JSON value and runs every string leaf through
+// the pipeline, rebuilding objects and arrays with the sanitized strings.
+// Object keys, numbers, booleans and nulls are kept as they are. If any leaf
+// is blocked the walk stops and the blocking result is returned.
+func sanitizeStructured(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	v any,
+) (any, *sanitizer.PipelineResult, error) {
+	v, err := normalizeJSON(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sanitizeValue(ctx, pipeline, v)
+}
+
+func sanitizeValue(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	v any,
+) (any, *sanitizer.PipelineResult, error) {
+	switch val := v.(type) {
+	case string:
+		return sanitizeString(ctx, pipeline, val)
+
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			clean, blocked, err := sanitizeValue(ctx, pipeline, item)
+			if err != nil || blocked != nil {
+				return nil, blocked, err
+			}
+			out[k] = clean
+		}
+		return out, nil, nil
+
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			clean, blocked, err := sanitizeValue(ctx, pipeline, item)
+			if err != nil || blocked != nil {
+				return nil, blocked, err
+			}
+			out[i] = clean
+		}
+		return out, nil, nil
+
+	default:
+		return v, nil, nil
+	}
+}
+
+// normalizeJSON converts v into the generic form produced by
+// encoding/json (map[string]any, []any, string, float64, bool, nil) so that
+// it can be walked. Values already in that form are returned unchanged.
+func normalizeJSON(v any) (any, error) {
+	switch v.(type) {
+	case nil, map[string]any, []any, string, float64, bool:
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding structured content: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decoding structured content: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sanitizeToolDefinition runs the parts of a tool definition that reach the
+// LLM's context through the pipeline: the title, description, annotation
+// title and the titles and descriptions in the input and output schemas.
+// The pipeline should not include the boundary scanner. It returns a sanitized copy of the tool, or the blocking result
+// if any field was blocked, in which case the tool must not be registered.
+func sanitizeToolDefinition(
+	ctx context.Context,
+	tool *mcp.Tool,
+	pipeline *sanitizer.Pipeline,
+) (*mcp.Tool, *sanitizer.PipelineResult, error) {
+	out := *tool
+
+	if blocked, err := sanitizeFields(ctx, pipeline, &out.Title, &out.Description); err != nil || blocked != nil {
+		return nil, blocked, err
+	}
+
+	if tool.Annotations != nil {
+		annotations := *tool.Annotations
+		clean, blocked, err := sanitizeString(ctx, pipeline, annotations.Title)
+		if err != nil || blocked != nil {
+			return nil, blocked, err
+		}
+		annotations.Title = clean
+		out.Annotations = &annotations
+	}
+
+	if tool.InputSchema != nil {
+		schema, blocked, err := sanitizeSchema(ctx, pipeline, tool.InputSchema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("input schema: %w", err)
+		}
+		if blocked != nil {
+			return nil, blocked, nil
+		}
+		out.InputSchema = schema
+	}
+
+	if tool.OutputSchema != nil {
+		schema, blocked, err := sanitizeSchema(ctx, pipeline, tool.OutputSchema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("output schema: %w", err)
+		}
+		if blocked != nil {
+			return nil, blocked, nil
+		}
+		out.OutputSchema = schema
+	}
+
+	return &out, nil, nil
+}
+
+// sanitizeSchema runs the title and description of a JSON schema, and of
+// every subschema nested under properties, items, $defs and the other
+// subschema keywords, through the pipeline. All other keywords (pattern,
+// enum, const, default, format, $schema and so on) give the schema its
+// meaning and are kept as they are, as are property names.
+func sanitizeSchema(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	schema any,
+) (any, *sanitizer.PipelineResult, error) {
+	v, err := normalizeJSON(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sanitizeSubschema(ctx, pipeline, v)
+}
+
+func sanitizeSubschema(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	v any,
+) (any, *sanitizer.PipelineResult, error) {
+	schema, ok := v.(map[string]any)
+	if !ok {
+		// Boolean schemas have nothing to sanitize.
+		return v, nil, nil
+	}
+
+	out := make(map[string]any, len(schema))
+	for k, val := range schema {
+		var (
+			clean   = val
+			blocked *sanitizer.PipelineResult
+			err     error
+		)
+		switch k {
+		case "title", "description":
+			if text, ok := val.(string); ok {
+				clean, blocked, err = sanitizeString(ctx, pipeline, text)
+			}
+		case "properties", "patternProperties", "dependentSchemas", "$defs", "definitions":
+			clean, blocked, err = sanitizeSchemaMap(ctx, pipeline, val)
+		case "items", "prefixItems", "additionalItems", "additionalProperties",
+			"unevaluatedItems", "unevaluatedProperties", "propertyNames", "contains",
+			"allOf", "anyOf", "oneOf", "not", "if", "then", "else":
+			clean, blocked, err = sanitizeSubschemas(ctx, pipeline, val)
+		}
+		if err != nil || blocked != nil {
+			return nil, blocked, err
+		}
+		out[k] = clean
+	}
+	return out, nil, nil
+}
+
+// sanitizeSubschemas sanitizes the value of a keyword that holds a schema or
+// an array of schemas.
+func sanitizeSubschemas(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	v any,
+) (any, *sanitizer.PipelineResult, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return sanitizeSubschema(ctx, pipeline, v)
+	}
+
+	out := make([]any, len(list))
+	for i, item := range list {
+		clean, blocked, err := sanitizeSubschema(ctx, pipeline, item)
+		if err != nil || blocked != nil {
+			return nil, blocked, err
+		}
+		out[i] = clean
+	}
+	return out, nil, nil
+}
+
+// sanitizeSchemaMap sanitizes the value of a keyword that maps names to
+// schemas, keeping the names.
+func sanitizeSchemaMap(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	v any,
+) (any, *sanitizer.PipelineResult, error) {
+	schemas, ok := v.(map[string]any)
+	if !ok {
+		return v, nil, nil
+	}
+
+	out := make(map[string]any, len(schemas))
+	for name, item := range schemas {
+		clean, blocked, err := sanitizeSubschema(ctx, pipeline, item)
+		if err != nil || blocked != nil {
+			return nil, blocked, err
+		}
+		out[name] = clean
+	}
+	return out, nil, nil
+}
+
+// sanitizeFields runs each field through the pipeline, replacing it with the
+// sanitized text. If any field is blocked it stops and returns the blocking
+// result; the fields sanitized so far have already been replaced.
+func sanitizeFields(
+	ctx context.Context,
+	pipeline *sanitizer.Pipeline,
+	fields ...*string,
+) (*sanitizer.PipelineResult, error) {
+	for _, field := range fields {
+		clean, blocked, err := sanitizeString(ctx, pipeline, *field)
+		if err != nil || blocked != nil {
+			return blocked, err
+		}
+		*field = clean
+	}
+	return nil, nil
+}
@@ -0,0 +1,197 @@
+// Package metrics exposes Prometheus metrics for the gateway: proxied tool
+// calls, downstream latency, sanitizer verdicts and downstream connection
+// state.
+//
+// A nil *Metrics is valid and records nothing, so callers need not check
+// whether metrics are enabled.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "easymcpgateway"
+
+// Tool call outcomes, used as the "outcome" label of the tool call counter.
+const (
+	OutcomeSuccess = "success"
+	// OutcomeError covers downstream failures and error results returned by
+	// the downstream tool.
+	OutcomeError = "error"
+	// OutcomeBlocked covers calls whose arguments or response were blocked
+	// by the gateway.
+	OutcomeBlocked = "blocked"
+	// OutcomeInvalid covers calls rejected for not matching the tool's
+	// input schema.
+	OutcomeInvalid = "invalid_arguments"
+	// OutcomeDenied covers calls the access policy does not allow for the
+	// calling client.
+	OutcomeDenied = "denied"
+)
+
+// Metrics holds the gateway's collectors and the registry they are
+// registered with.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	toolCalls  *prometheus.CounterVec
+	downstream *prometheus.HistogramVec
+	verdicts   *prometheus.CounterVec
+	threats    *prometheus.CounterVec
+	reconnects *prometheus.CounterVec
+}
+
+// New creates a Metrics with its own registry, including the standard Go
+// runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tool_calls_total",
+			Help:      "Proxied tool calls by downstream server, tool and outcome.",
+		}, []string{"server", "tool", "outcome"}),
+		downstream: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "downstream_call_duration_seconds",
+			Help:      "Latency of tool calls forwarded to downstream servers.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"server", "tool"}),
+		verdicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sanitizer_verdicts_total",
+			Help:      "Sanitizer scan results by downstream server, scanner, verdict and mode.",
+		}, []string{"server", "scanner", "verdict", "mode"}),
+		threats: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "threats_total",
+			Help:      "Threats reported by the sanitizer, by downstream server, scanner, threat type and mode.",
+		}, []string{"server", "scanner", "type", "mode"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downstream_reconnect_attempts_total",
+			Help:      "Health check connection attempts by downstream server and result.",
+		}, []string{"server", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.toolCalls,
+		m.downstream,
+		m.verdicts,
+		m.threats,
+		m.reconnects,
+	)
+	return m
+}
+
+// Handler returns the HTTP handler that serves the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ToolCall records a proxied tool call and its outcome.
+func (m *Metrics) ToolCall(server, tool, outcome string) {
+	if m == nil {
+		return
+	}
+	m.toolCalls.WithLabelValues(server, tool, outcome).Inc()
+}
+
+// DownstreamCall records the latency of a call forwarded to a downstream
+// server.
+func (m *Metrics) DownstreamCall(server, tool string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.downstream.WithLabelValues(server, tool).Observe(d.Seconds())
+}
+
+// Reconnect records a health check connection attempt for a downstream
+// server.
+func (m *Metrics) Reconnect(server string, ok bool) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if !ok {
+		result = "failure"
+	}
+	m.reconnects.WithLabelValues(server, result).Inc()
+}
+
+// ScanObserver returns a sanitizer.Observer that records the verdict and
+// threats of every scan run for the given downstream server. The "mode"
+// label is "report" for scanners in report mode, whose verdicts were not
+// applied, and "enforce" otherwise. It returns nil when m is nil.
+func (m *Metrics) ScanObserver(server string) sanitizer.Observer {
+	if m == nil {
+		return nil
+	}
+	return func(_ context.Context, sr sanitizer.ScanResult) {
+		mode := "enforce"
+		if sr.Reported {
+			mode = "report"
+		}
+		m.verdicts.WithLabelValues(server, sr.ScannerName, sr.Verdict.String(), mode).Inc()
+		for _, threat := range sr.Threats {
+			m.threats.WithLabelValues(server, sr.ScannerName, threatType(threat), mode).Inc()
+		}
+	}
+}
+
+// threatType reduces a threat description to the part before its detail,
+// e.g. "secret detected" for "secret detected: jwt", so the label does not
+// grow with matched patterns or URLs.
+func threatType(threat string) string {
+	kind, _, _ := strings.Cut(threat, ":")
+	return strings.TrimSpace(kind)
+}
+
+// ConnectionState reports, for each configured downstream server, whether
+// it is currently connected.
+type ConnectionState func() map[string]bool
+
+// TrackConnections exposes a gauge per downstream server that is 1 while
+// the server is connected and 0 otherwise. state is called on every scrape.
+func (m *Metrics) TrackConnections(state ConnectionState) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(&connectionCollector{state: state})
+}
+
+var connectedDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "downstream", "connected"),
+	"Whether the downstream server is connected (1) or pending (0).",
+	[]string{"server"}, nil,
+)
+
+// connectionCollector reads the connection state at scrape time so the
+// gauges cannot drift from the downstream manager.
+type connectionCollector struct {
+	state ConnectionState
+}
+
+func (c *connectionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- connectedDesc
+}
+
+func (c *connectionCollector) Collect(ch chan<- prometheus.Metric) {
+	for server, connected := range c.state() {
+		v := 0.0
+		if connected {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(connectedDesc, prometheus.GaugeValue, v, server)
+	}
+}
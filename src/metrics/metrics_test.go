@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
+)
+
+// scrape returns the text exposition served by m.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+func assertContains(t *testing.T, body string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(body, w) {
+			t.Errorf("metrics missing %s", w)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ToolCall("srv", "tool", OutcomeSuccess)
+	m.DownstreamCall("srv", "tool", time.Second)
+	m.Reconnect("srv", false)
+	m.TrackConnections(func() map[string]bool { return nil })
+	if m.ScanObserver("srv") != nil {
+		t.Error("nil metrics should return a nil observer")
+	}
+}
+
+func TestMetrics_ToolCalls(t *testing.T) {
+	m := New()
+	m.ToolCall("fs", "read", OutcomeSuccess)
+	m.ToolCall("fs", "read", OutcomeSuccess)
+	m.ToolCall("fs", "read", OutcomeBlocked)
+	m.DownstreamCall("fs", "read", 20*time.Millisecond)
+
+	assertContains(t, scrape(t, m),
+		`easymcpgateway_tool_calls_total{outcome="success",server="fs",tool="read"} 2`,
+		`easymcpgateway_tool_calls_total{outcome="blocked",server="fs",tool="read"} 1`,
+		`easymcpgateway_downstream_call_duration_seconds_bucket{server="fs",tool="read",le="0.025"} 1`,
+	)
+}
+
+func TestMetrics_ScanObserver(t *testing.T) {
+	m := New()
+	observe := m.ScanObserver("web")
+	observe(context.Background(), sanitizer.ScanResult{ScannerName: "url", Verdict: sanitizer.VerdictPass})
+	observe(context.Background(), sanitizer.ScanResult{
+		ScannerName: "secrets",
+		Verdict:     sanitizer.VerdictModify,
+		Threats:     []string{"secret detected: aws_access_key", "secret detected: jwt"},
+	})
+	observe(context.Background(), sanitizer.ScanResult{
+		ScannerName: "unicode",
+		Verdict:     sanitizer.VerdictModify,
+		Threats:     []string{"invisible/control characters removed"},
+	})
+
+	assertContains(t, scrape(t, m),
+		`easymcpgateway_sanitizer_verdicts_total{mode="enforce",scanner="url",server="web",verdict="pass"} 1`,
+		`easymcpgateway_sanitizer_verdicts_total{mode="enforce",scanner="secrets",server="web",verdict="modify"} 1`,
+		`easymcpgateway_threats_total{mode="enforce",scanner="secrets",server="web",type="secret detected"} 2`,
+		`easymcpgateway_threats_total{mode="enforce",scanner="unicode",server="web",type="invisible/control characters removed"} 1`,
+	)
+}
+
+func TestMetrics_Connections(t *testing.T) {
+	m := New()
+	connected := map[string]bool{"a": true, "b": false}
+	m.TrackConnections(func() map[string]bool { return connected })
+	m.Reconnect("b", false)
+
+	assertContains(t, scrape(t, m),
+		`easymcpgateway_downstream_connected{server="a"} 1`,
+		`easymcpgateway_downstream_connected{server="b"} 0`,
+		`easymcpgateway_downstream_reconnect_attempts_total{result="failure",server="b"} 1`,
+	)
+
+	connected["b"] = true
+	assertContains(t, scrape(t, m), `easymcpgateway_downstream_connected{server="b"} 1`)
+}
@@ -0,0 +1,39 @@
+package pinning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// pinnedFields are the parts of a tool definition covered by the
+// fingerprint. encoding/json sorts map keys, so schemas hash identically
+// regardless of the order the server sent them in.
+type pinnedFields struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	InputSchema  any                  `json:"inputSchema"`
+	OutputSchema any                  `json:"outputSchema"`
+	Annotations  *mcp.ToolAnnotations `json:"annotations"`
+}
+
+// Fingerprint returns a stable hash of a downstream tool definition: its
+// name, description, input and output schema and annotations.
+func Fingerprint(tool *mcp.Tool) (string, error) {
+	data, err := json.Marshal(pinnedFields{
+		Name:         tool.Name,
+		Description:  tool.Description,
+		InputSchema:  tool.InputSchema,
+		OutputSchema: tool.OutputSchema,
+		Annotations:  tool.Annotations,
+	})
+	if err != nil {
+		return "", fmt.Errorf("fingerprinting tool %s: %w", tool.Name, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
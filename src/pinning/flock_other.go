@@ -0,0 +1,10 @@
+//go:build !unix
+
+package pinning
+
+// lockFile is a no-op where advisory file locks are unavailable: saves still
+// merge with the file on disk, but two processes saving at the same instant
+// may lose one of the changes.
+func lockFile(string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,365 @@
+// Package pinning fingerprints downstream tool definitions and persists the
+// fingerprints in a lockfile, so that a server silently changing a tool's
+// description or schema after it was approved (a "rug pull") is detected.
+package pinning
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const lockfileVersion = 1
+
+// Status is the outcome of checking a tool fingerprint against the lockfile.
+type Status int
+
+const (
+	// StatusNew means the tool was not in the lockfile and has now been
+	// pinned (trust on first use).
+	StatusNew Status = iota
+	// StatusMatch means the fingerprint matches the pinned one.
+	StatusMatch
+	// StatusChanged means the fingerprint differs from the pinned one. The
+	// new fingerprint is recorded as pending until approved.
+	StatusChanged
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusNew:
+		return "new"
+	case StatusMatch:
+		return "match"
+	case StatusChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Entry is the lockfile record for one tool.
+type Entry struct {
+	// Fingerprint is the approved definition hash.
+	Fingerprint string `json:"fingerprint"`
+	// Pending is the most recently observed hash when it differs from the
+	// approved one.
+	Pending string `json:"pending,omitempty"`
+}
+
+// Lockfile holds pinned tool fingerprints keyed by namespaced tool name.
+// It is safe for concurrent use, and for use by several processes at once:
+// the running gateway and the approve command share the file. Check picks
+// up changes other processes have saved, and Save merges this process's
+// changes into the file as it is on disk rather than overwriting it.
+type Lockfile struct {
+	path string
+
+	mu    sync.Mutex
+	tools map[string]*Entry
+	// info describes the file tools was last read from or written to; nil
+	// if there was no file. It tells refresh when another process has
+	// replaced the file.
+	info fs.FileInfo
+	// changes records, in order, the checks and approvals that changed
+	// tools since the last Save, so they can be replayed onto the file as
+	// it is on disk.
+	changes []change
+	// external holds the tools whose entries other processes have changed
+	// since the last call to Reload.
+	external map[string]bool
+}
+
+// change is a Check or Approve of one tool's fingerprint.
+type change struct {
+	name        string
+	fingerprint string
+	approve     bool
+}
+
+type lockfileJSON struct {
+	Version int               `json:"version"`
+	Tools   map[string]*Entry `json:"tools"`
+}
+
+// Load reads the lockfile at path. A missing file yields an empty lockfile
+// that will be created on the first Save.
+func Load(path string) (*Lockfile, error) {
+	tools, info, err := readLockfile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Lockfile{path: path, tools: tools, info: info}, nil
+}
+
+// readLockfile reads the tools in the lockfile at path, along with the
+// file's info. A missing file has no tools and nil info.
+func readLockfile(path string) (map[string]*Entry, fs.FileInfo, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]*Entry), nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading lockfile %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading lockfile %s: %w", path, err)
+	}
+
+	var lf lockfileJSON
+	if err := json.Unmarshal(data, &lf); err != nil {
+		return nil, nil, fmt.Errorf("parsing lockfile %s: %w", path, err)
+	}
+	if lf.Version != lockfileVersion {
+		return nil, nil, fmt.Errorf("lockfile %s: unsupported version %d", path, lf.Version)
+	}
+	if lf.Tools == nil {
+		lf.Tools = make(map[string]*Entry)
+	}
+	return lf.Tools, info, nil
+}
+
+// Path returns the file the lockfile is loaded from and saved to.
+func (l *Lockfile) Path() string { return l.path }
+
+// refresh re-reads the file if another process has saved it since it was
+// last read, and replays the unsaved changes of this process on top. A
+// file that cannot be read is ignored; Save reports the error. Must be
+// called with l.mu held.
+func (l *Lockfile) refresh() {
+	info, err := os.Stat(l.path)
+	if err != nil || sameFile(l.info, info) {
+		return
+	}
+
+	tools, info, err := readLockfile(l.path)
+	if err != nil {
+		return
+	}
+	l.info = info
+	l.merge(tools)
+}
+
+// merge replaces l.tools with tools read from the file, replays the unsaved
+// changes on top and records the entries that differ as a result, which
+// other processes must have changed. Must be called with l.mu held.
+func (l *Lockfile) merge(tools map[string]*Entry) {
+	previous := l.tools
+	l.tools = tools
+	l.replay()
+
+	for name, entry := range l.tools {
+		if old, ok := previous[name]; !ok || *old != *entry {
+			l.markExternal(name)
+		}
+	}
+	for name := range previous {
+		if _, ok := l.tools[name]; !ok {
+			l.markExternal(name)
+		}
+	}
+}
+
+func (l *Lockfile) markExternal(name string) {
+	if l.external == nil {
+		l.external = make(map[string]bool)
+	}
+	l.external[name] = true
+}
+
+// Reload re-reads the file if another process has saved it, and returns
+// the sorted names of the tools whose entries other processes have changed
+// since the last call to Reload, for example by approving them. Changes
+// picked up earlier by Check, Pending, Approve or Save are included.
+func (l *Lockfile) Reload() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refresh()
+	names := slices.Sorted(maps.Keys(l.external))
+	l.external = nil
+	return names
+}
+
+// sameFile reports whether a and b describe the same, unmodified file.
+func sameFile(a, b fs.FileInfo) bool {
+	return a != nil && b != nil &&
+		os.SameFile(a, b) &&
+		a.ModTime().Equal(b.ModTime()) &&
+		a.Size() == b.Size()
+}
+
+// replay applies the unsaved changes to l.tools in the order they were
+// made. Must be called with l.mu held.
+func (l *Lockfile) replay() {
+	for _, c := range l.changes {
+		if c.approve {
+			approve(l.tools, c.name, c.fingerprint)
+		} else {
+			check(l.tools, c.name, c.fingerprint)
+		}
+	}
+}
+
+// Check compares fingerprint against the pinned entry for name and updates
+// the lockfile accordingly: unknown tools are pinned, changed tools have the
+// new fingerprint recorded as pending, and a tool that has reverted to its
+// pinned definition has its pending fingerprint cleared. Approvals saved by
+// other processes are taken into account.
+func (l *Lockfile) Check(name, fingerprint string) Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refresh()
+	status, changed := check(l.tools, name, fingerprint)
+	if changed {
+		l.changes = append(l.changes, change{name: name, fingerprint: fingerprint})
+	}
+	return status
+}
+
+// check applies Check to tools and reports whether the entry changed.
+func check(tools map[string]*Entry, name, fingerprint string) (Status, bool) {
+	entry, ok := tools[name]
+	if !ok {
+		tools[name] = &Entry{Fingerprint: fingerprint}
+		return StatusNew, true
+	}
+
+	if entry.Fingerprint == fingerprint {
+		if entry.Pending != "" {
+			entry.Pending = ""
+			return StatusMatch, true
+		}
+		return StatusMatch, false
+	}
+
+	if entry.Pending != fingerprint {
+		entry.Pending = fingerprint
+		return StatusChanged, true
+	}
+	return StatusChanged, false
+}
+
+// Pending returns the sorted names of tools with an unapproved change.
+func (l *Lockfile) Pending() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refresh()
+	var names []string
+	for name, entry := range l.tools {
+		if entry.Pending != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Approve accepts the pending fingerprint of each named tool as its new
+// pinned fingerprint. Returns an error naming any tool without a pending
+// change; no tools are approved in that case.
+func (l *Lockfile) Approve(names ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refresh()
+	for _, name := range names {
+		entry, ok := l.tools[name]
+		if !ok || entry.Pending == "" {
+			return fmt.Errorf("tool %s has no pending change", name)
+		}
+	}
+
+	for _, name := range names {
+		fingerprint := l.tools[name].Pending
+		approve(l.tools, name, fingerprint)
+		l.changes = append(l.changes, change{name: name, fingerprint: fingerprint, approve: true})
+	}
+	return nil
+}
+
+// approve pins fingerprint for name in tools, clearing the pending
+// fingerprint if it is the one approved. A tool that has changed again
+// since keeps its newer pending fingerprint.
+func approve(tools map[string]*Entry, name, fingerprint string) {
+	entry, ok := tools[name]
+	if !ok {
+		entry = &Entry{}
+		tools[name] = entry
+	}
+	entry.Fingerprint = fingerprint
+	if entry.Pending == fingerprint {
+		entry.Pending = ""
+	}
+}
+
+// Save writes the changes made since the lockfile was loaded or last saved,
+// if any. It holds an exclusive lock on path+".lock" while it re-reads the
+// file, merges the changes into it and replaces it atomically, so that
+// concurrent saves from other processes are not lost.
+func (l *Lockfile) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.changes) == 0 {
+		return nil
+	}
+
+	unlock, err := lockFile(l.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking lockfile %s: %w", l.path, err)
+	}
+	defer unlock()
+
+	tools, _, err := readLockfile(l.path)
+	if err != nil {
+		return err
+	}
+	l.merge(tools)
+
+	if err := l.write(); err != nil {
+		return err
+	}
+
+	l.info, _ = os.Stat(l.path)
+	l.changes = nil
+	return nil
+}
+
+// write replaces the file with l.tools atomically. Must be called with l.mu
+// and the file lock held.
+func (l *Lockfile) write() error {
+	data, err := json.MarshalIndent(lockfileJSON{Version: lockfileVersion, Tools: l.tools}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding lockfile: %w", err)
+	}
+	data = append(data, '\n')
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing lockfile %s: %w", l.path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing lockfile %s: %w", l.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing lockfile %s: %w", l.path, err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("writing lockfile %s: %w", l.path, err)
+	}
+	return nil
+}
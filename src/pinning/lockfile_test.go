@@ -0,0 +1,221 @@
+package pinning
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoad_missingFile(t *testing.T) {
+	l, err := Load(filepath.Join(t.TempDir(), "tools.lock.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(l.Pending()) != 0 {
+		t.Error("expected empty lockfile")
+	}
+}
+
+func TestLoad_invalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.lock.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestLoad_unsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.lock.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "tools": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
+
+func TestCheck_lifecycle(t *testing.T) {
+	l, err := Load(filepath.Join(t.TempDir(), "tools.lock.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := l.Check("a__t", "v1"); got != StatusNew {
+		t.Errorf("first check = %v, want new", got)
+	}
+	if got := l.Check("a__t", "v1"); got != StatusMatch {
+		t.Errorf("same fingerprint = %v, want match", got)
+	}
+	if got := l.Check("a__t", "v2"); got != StatusChanged {
+		t.Errorf("new fingerprint = %v, want changed", got)
+	}
+	if !slices.Equal(l.Pending(), []string{"a__t"}) {
+		t.Errorf("pending = %v, want [a__t]", l.Pending())
+	}
+
+	// Reverting to the pinned definition clears the pending change.
+	if got := l.Check("a__t", "v1"); got != StatusMatch {
+		t.Errorf("reverted fingerprint = %v, want match", got)
+	}
+	if len(l.Pending()) != 0 {
+		t.Errorf("pending = %v, want none", l.Pending())
+	}
+}
+
+func TestApprove_promotesPending(t *testing.T) {
+	l, err := Load(filepath.Join(t.TempDir(), "tools.lock.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Check("a__t", "v1")
+	l.Check("a__t", "v2")
+
+	if err := l.Approve("a__t"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if got := l.Check("a__t", "v2"); got != StatusMatch {
+		t.Errorf("approved fingerprint = %v, want match", got)
+	}
+}
+
+func TestApprove_noPendingChange(t *testing.T) {
+	l, err := Load(filepath.Join(t.TempDir(), "tools.lock.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Check("a__t", "v1")
+	l.Check("b__t", "v1")
+	l.Check("b__t", "v2")
+
+	if err := l.Approve("b__t", "a__t"); err == nil {
+		t.Fatal("expected error approving a tool without a pending change")
+	}
+	if !slices.Equal(l.Pending(), []string{"b__t"}) {
+		t.Error("expected no tools approved after error")
+	}
+}
+
+func TestSave_roundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.lock.json")
+	l, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Check("a__t", "v1")
+	l.Check("a__t", "v2")
+
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := reloaded.Check("a__t", "v1"); got != StatusMatch {
+		t.Errorf("pinned fingerprint after reload = %v, want match", got)
+	}
+}
+
+func TestSave_mergesChangesFromOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.lock.json")
+	gw, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw.Check("a__t", "v1")
+	gw.Check("a__t", "v2")
+	if err := gw.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	cli, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Approve("a__t"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+
+	// An unsaved change in the gateway, made before the approval is saved.
+	gw.Check("b__t", "v1")
+	if err := cli.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The gateway sees the approval without reloading.
+	if got := gw.Check("a__t", "v2"); got != StatusMatch {
+		t.Errorf("approved fingerprint = %v, want match", got)
+	}
+	if err := gw.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := reloaded.Pending(); len(pending) != 0 {
+		t.Errorf("pending = %v, want the approval kept", pending)
+	}
+	if got := reloaded.Check("a__t", "v2"); got != StatusMatch {
+		t.Errorf("a__t = %v, want match", got)
+	}
+	if got := reloaded.Check("b__t", "v1"); got != StatusMatch {
+		t.Errorf("b__t = %v, want the gateway's pin kept", got)
+	}
+}
+
+func TestReload_reportsChangesFromOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.lock.json")
+	gw, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw.Check("a__t", "v1")
+	gw.Check("a__t", "v2")
+	gw.Check("b__t", "v1")
+	if err := gw.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if changed := gw.Reload(); len(changed) != 0 {
+		t.Errorf("own changes reported: %v", changed)
+	}
+
+	cli, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Approve("a__t"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if err := cli.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A check picking up the approval first does not hide it from Reload.
+	gw.Check("b__t", "v1")
+	if changed := gw.Reload(); !slices.Equal(changed, []string{"a__t"}) {
+		t.Errorf("changed = %v, want [a__t]", changed)
+	}
+	if changed := gw.Reload(); len(changed) != 0 {
+		t.Errorf("changed = %v on second reload, want none", changed)
+	}
+}
+
+func TestSave_unchangedDoesNotWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.lock.json")
+	l, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected no file to be written for an unchanged lockfile")
+	}
+}
@@ -0,0 +1,263 @@
+package sanitizer
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PIIEntity identifies a kind of personal data detected by the PIIScanner.
+type PIIEntity string
+
+const (
+	PIIEmail      PIIEntity = "email"
+	PIIPhone      PIIEntity = "phone"
+	PIIIBAN       PIIEntity = "iban"
+	PIICreditCard PIIEntity = "credit_card"
+	PIINationalID PIIEntity = "national_id"
+)
+
+// PIIAction is what the PIIScanner does with detected personal data.
+type PIIAction int
+
+const (
+	// PIIRedact replaces each value with [REDACTED:<entity>].
+	PIIRedact PIIAction = iota
+	// PIIHash replaces each value with a keyed hash such as
+	// [email:3f9a1c2b7d4e], so the same value always maps to the same
+	// pseudonym and the LLM can still correlate records.
+	PIIHash
+	// PIIBlock rejects content containing personal data.
+	PIIBlock
+)
+
+type piiDetector struct {
+	entity   PIIEntity
+	patterns []*regexp.Regexp
+	valid    func(match string) bool
+}
+
+// piiDetectors are checked in order. Credit cards and IBANs run before
+// phone numbers so that long digit groups are labelled with the stricter,
+// checksum-validated entity.
+var piiDetectors = []piiDetector{
+	{
+		entity:   PIIEmail,
+		patterns: []*regexp.Regexp{regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	},
+	{
+		entity:   PIICreditCard,
+		patterns: []*regexp.Regexp{regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)},
+		valid:    luhnValid,
+	},
+	{
+		entity:   PIIIBAN,
+		patterns: []*regexp.Regexp{regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`)},
+		valid:    ibanValid,
+	},
+	{
+		entity: PIINationalID,
+		patterns: []*regexp.Regexp{
+			// US social security number.
+			regexp.MustCompile(`\b(?:00[1-9]|0[1-9]\d|[1-578]\d{2}|6[0-57-9]\d|66[0-57-9])-(?:0[1-9]|[1-9]\d)-(?:000[1-9]|00[1-9]\d|0[1-9]\d{2}|[1-9]\d{3})\b`),
+			// UK national insurance number.
+			regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+		},
+	},
+	{
+		entity: PIIPhone,
+		patterns: []*regexp.Regexp{
+			// International format: +44 20 7946 0958, +1-202-555-0143.
+			regexp.MustCompile(`\+\d{1,3}[ .-]?(?:\(\d{1,4}\)[ .-]?)?\d{1,4}(?:[ .-]?\d{2,4}){1,4}\b`),
+			// North American format: (202) 555-0143, 202-555-0143.
+			regexp.MustCompile(`(?:\(\d{3}\) ?|\b\d{3}[.-])\d{3}[.-]\d{4}\b`),
+			// National trunk prefix: 020 7946 0958, 07700 900123.
+			regexp.MustCompile(`\b0\d{2,4} \d{3,4} ?\d{3,4}\b`),
+		},
+		valid: func(match string) bool {
+			n := len(digitsOnly(match))
+			return n >= 7 && n <= 15
+		},
+	},
+}
+
+// processHashKey is used when no hash key is configured, so pseudonyms are
+// stable for the lifetime of the process but cannot be reversed by
+// hashing candidate values offline.
+var processHashKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+})
+
+// PIIScanner detects personal data such as email addresses, phone numbers,
+// IBANs, payment card numbers and national ID numbers, and redacts, hashes
+// or blocks it according to its action.
+type PIIScanner struct {
+	detectors []piiDetector
+	action    PIIAction
+	hashKey   []byte
+}
+
+// NewPIIScanner builds a scanner for the given entities. An empty hashKey
+// uses a random per-process key for PIIHash.
+func NewPIIScanner(entities []PIIEntity, action PIIAction, hashKey []byte) (*PIIScanner, error) {
+	detectors := make([]piiDetector, 0, len(entities))
+	for _, d := range piiDetectors {
+		for _, e := range entities {
+			if e == d.entity {
+				detectors = append(detectors, d)
+				break
+			}
+		}
+	}
+	if len(detectors) != len(entities) {
+		return nil, fmt.Errorf("unknown or duplicate PII entity in %v", entities)
+	}
+
+	if len(hashKey) == 0 {
+		hashKey = processHashKey()
+	}
+
+	return &PIIScanner{detectors: detectors, action: action, hashKey: hashKey}, nil
+}
+
+func (s *PIIScanner) Name() string { return "pii" }
+
+func (s *PIIScanner) Scan(_ context.Context, content string) (ScanResult, error) {
+	current := content
+	var found []string
+
+	for _, d := range s.detectors {
+		n := 0
+		for _, re := range d.patterns {
+			current = re.ReplaceAllStringFunc(current, func(match string) string {
+				if d.valid != nil && !d.valid(match) {
+					return match
+				}
+				n++
+				return s.replacement(d.entity, match)
+			})
+		}
+		if n > 0 {
+			found = append(found, fmt.Sprintf("PII detected: %s", d.entity))
+		}
+	}
+
+	if len(found) == 0 {
+		return ScanResult{
+			Verdict:     VerdictPass,
+			Content:     content,
+			ScannerName: s.Name(),
+		}, nil
+	}
+
+	if s.action == PIIBlock {
+		return ScanResult{
+			Verdict:     VerdictBlock,
+			Content:     content,
+			Threats:     found,
+			ScannerName: s.Name(),
+		}, nil
+	}
+
+	return ScanResult{
+		Verdict:     VerdictModify,
+		Content:     current,
+		Threats:     found,
+		ScannerName: s.Name(),
+	}, nil
+}
+
+func (s *PIIScanner) replacement(entity PIIEntity, match string) string {
+	if s.action != PIIHash {
+		return "[REDACTED:" + string(entity) + "]"
+	}
+
+	mac := hmac.New(sha256.New, s.hashKey)
+	mac.Write([]byte(entity))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalizePII(entity, match)))
+	return "[" + string(entity) + ":" + hex.EncodeToString(mac.Sum(nil)[:6]) + "]"
+}
+
+// normalizePII reduces a value to a canonical form so that differently
+// formatted copies of it hash to the same pseudonym.
+func normalizePII(entity PIIEntity, match string) string {
+	switch entity {
+	case PIIEmail:
+		return strings.ToLower(match)
+	case PIIPhone, PIICreditCard:
+		return digitsOnly(match)
+	default:
+		return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(match))
+	}
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// luhnValid reports whether s, ignoring separators, is a 13 to 19 digit
+// number with a valid Luhn check digit.
+func luhnValid(s string) bool {
+	digits := digitsOnly(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ibanValid reports whether s, ignoring spaces, passes the ISO 13616
+// mod-97 check.
+func ibanValid(s string) bool {
+	iban := strings.ReplaceAll(s, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	var b strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			fmt.Fprintf(&b, "%d", r-'A'+10)
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(b.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
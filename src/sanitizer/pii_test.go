@@ -0,0 +1,150 @@
+package sanitizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+var allPIIEntities = []PIIEntity{PIIEmail, PIIPhone, PIIIBAN, PIICreditCard, PIINationalID}
+
+func TestPIIScanner_Clean(t *testing.T) {
+	s, err := NewPIIScanner(allPIIEntities, PIIRedact, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inputs := []string{
+		"Ticket #4821 was closed on 2024-03-01 after 3 replies.",
+		"Order total: 1234.56 GBP",
+		"card ending 4242",
+		"4111 1111 1111 1112",         // fails Luhn
+		"GB82 WEST 1234 5698 7654 33", // fails mod-97
+	}
+	for _, input := range inputs {
+		res, err := s.Scan(context.Background(), input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Verdict != VerdictPass {
+			t.Errorf("verdict = %v, want Pass for %q (content %q)", res.Verdict, input, res.Content)
+		}
+	}
+}
+
+func TestPIIScanner_Redact(t *testing.T) {
+	s, err := NewPIIScanner(allPIIEntities, PIIRedact, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"email", "Contact jane.doe@example.com today", "Contact [REDACTED:email] today"},
+		{"international phone", "Call +44 20 7946 0958.", "Call [REDACTED:phone]."},
+		{"us phone", "Call (202) 555-0143", "Call [REDACTED:phone]"},
+		{"uk phone", "Call 020 7946 0958", "Call [REDACTED:phone]"},
+		{"credit card", "Card 4111 1111 1111 1111 on file", "Card [REDACTED:credit_card] on file"},
+		{"iban", "IBAN GB82 WEST 1234 5698 7654 32", "IBAN [REDACTED:iban]"},
+		{"ssn", "SSN 123-45-6789", "SSN [REDACTED:national_id]"},
+		{"nino", "NI number AB 12 34 56 C", "NI number [REDACTED:national_id]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.Scan(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Verdict != VerdictModify {
+				t.Fatalf("verdict = %v, want Modify", res.Verdict)
+			}
+			if res.Content != tt.want {
+				t.Errorf("content = %q, want %q", res.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestPIIScanner_Hash(t *testing.T) {
+	s, err := NewPIIScanner(allPIIEntities, PIIHash, []byte("test-key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := s.Scan(context.Background(), "from Jane.Doe@Example.com to jane.doe@example.com cc bob@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Verdict != VerdictModify {
+		t.Fatalf("verdict = %v, want Modify", res.Verdict)
+	}
+
+	fields := strings.Fields(res.Content)
+	from, to, cc := fields[1], fields[3], fields[5]
+	if !strings.HasPrefix(from, "[email:") {
+		t.Fatalf("expected hashed email, got %q", res.Content)
+	}
+	if from != to {
+		t.Errorf("same address should hash identically: %s vs %s", from, to)
+	}
+	if from == cc {
+		t.Error("different addresses should hash differently")
+	}
+
+	other, err := NewPIIScanner(allPIIEntities, PIIHash, []byte("other-key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res2, err := other.Scan(context.Background(), "jane.doe@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res2.Content == to {
+		t.Error("different keys should produce different pseudonyms")
+	}
+}
+
+func TestPIIScanner_Block(t *testing.T) {
+	s, err := NewPIIScanner(allPIIEntities, PIIBlock, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	input := "jane.doe@example.com"
+	res, err := s.Scan(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Verdict != VerdictBlock {
+		t.Errorf("verdict = %v, want Block", res.Verdict)
+	}
+	if res.Content != input {
+		t.Error("blocked content should be returned unchanged")
+	}
+}
+
+func TestPIIScanner_EntitySelection(t *testing.T) {
+	s, err := NewPIIScanner([]PIIEntity{PIIEmail}, PIIRedact, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := s.Scan(context.Background(), "jane@example.com +44 20 7946 0958")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "[REDACTED:email] +44 20 7946 0958"
+	if res.Content != want {
+		t.Errorf("content = %q, want %q (only email enabled)", res.Content, want)
+	}
+}
+
+func TestNewPIIScanner_UnknownEntity(t *testing.T) {
+	if _, err := NewPIIScanner([]PIIEntity{"passport"}, PIIRedact, nil); err == nil {
+		t.Error("expected error for unknown entity")
+	}
+}
@@ -1,22 +1,78 @@
 package sanitizer
 
-import "context"
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of scanner spans.
+const tracerName = "github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/sanitizer"
 
 // Pipeline executes an ordered sequence of Scanners against content.
 // On VerdictBlock it short-circuits. On VerdictModify it threads the
-// modified content into subsequent scanners.
+// modified content into subsequent scanners. Scanners in report mode (see
+// ReportOnly) never block or modify.
 type Pipeline struct {
 	scanners []Scanner
+	observer Observer
+	// report holds the names of scanners in report mode.
+	report map[string]bool
 }
 
+// Observer receives the result of every scan a pipeline runs, e.g. to
+// record metrics.
+type Observer func(ctx context.Context, sr ScanResult)
+
 // NewPipeline creates a pipeline from the given scanners. Execution
 // order matches the slice order.
 func NewPipeline(scanners ...Scanner) *Pipeline {
 	return &Pipeline{scanners: scanners}
 }
 
+// Without returns a pipeline with the named scanners removed, preserving
+// the order of the rest. Used for content that must not be rewritten by
+// presentation scanners, e.g. structured fields that should not be wrapped
+// in boundary markers.
+func (p *Pipeline) Without(names ...string) *Pipeline {
+	scanners := make([]Scanner, 0, len(p.scanners))
+	for _, s := range p.scanners {
+		if !slices.Contains(names, s.Name()) {
+			scanners = append(scanners, s)
+		}
+	}
+	return &Pipeline{scanners: scanners, observer: p.observer, report: p.report}
+}
+
+// ReportOnly returns a copy of the pipeline in which the named scanners run
+// in report mode: they still scan, and their results are recorded with
+// Reported set, but their verdicts are not applied. Used to measure a
+// scanner's false positives before enforcing it.
+func (p *Pipeline) ReportOnly(names ...string) *Pipeline {
+	report := maps.Clone(p.report)
+	if report == nil {
+		report = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		report[name] = true
+	}
+	return &Pipeline{scanners: p.scanners, observer: p.observer, report: report}
+}
+
+// WithObserver returns a copy of the pipeline that reports each scan result
+// to fn. Pipelines derived from it with Without keep the observer.
+func (p *Pipeline) WithObserver(fn Observer) *Pipeline {
+	return &Pipeline{scanners: p.scanners, observer: fn, report: p.report}
+}
+
 // Process runs all scanners in order and returns an aggregated result.
+// When ctx carries a recording span, each scan gets a child span.
 func (p *Pipeline) Process(ctx context.Context, content string) (PipelineResult, error) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName)
 	current := content
 	result := PipelineResult{
 		FinalVerdict: VerdictPass,
@@ -24,12 +80,20 @@ func (p *Pipeline) Process(ctx context.Context, content string) (PipelineResult,
 	}
 
 	for _, s := range p.scanners {
-		sr, err := s.Scan(ctx, current)
+		reported := p.report[s.Name()]
+		sr, err := scan(ctx, tracer, s, current, reported)
 		if err != nil {
 			return result, err
 		}
+		sr.Reported = reported
+		if p.observer != nil {
+			p.observer(ctx, sr)
+		}
 
 		result.ScanResults = append(result.ScanResults, sr)
+		if reported {
+			continue
+		}
 		result.AllThreats = append(result.AllThreats, sr.Threats...)
 
 		switch sr.Verdict {
@@ -50,3 +114,24 @@ func (p *Pipeline) Process(ctx context.Context, content string) (PipelineResult,
 	result.FinalContent = current
 	return result, nil
 }
+
+// scan runs a single scanner inside a span recording its verdict.
+func scan(ctx context.Context, tracer trace.Tracer, s Scanner, content string, reported bool) (ScanResult, error) {
+	ctx, span := tracer.Start(ctx, "scan "+s.Name(), trace.WithAttributes(
+		attribute.String("sanitizer.scanner", s.Name()),
+		attribute.Bool("sanitizer.reported", reported),
+	))
+	defer span.End()
+
+	sr, err := s.Scan(ctx, content)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return sr, err
+	}
+	span.SetAttributes(
+		attribute.String("sanitizer.verdict", sr.Verdict.String()),
+		attribute.Int("sanitizer.threats", len(sr.Threats)),
+	)
+	return sr, nil
+}
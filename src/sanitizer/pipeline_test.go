@@ -3,7 +3,12 @@ package sanitizer
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // stubScanner is a test helper that returns a preconfigured result.
@@ -154,3 +159,111 @@ func (s *trackingScanner) Scan(_ context.Context, content string) (ScanResult, e
 	*s.ran = true
 	return ScanResult{Verdict: VerdictPass, Content: content}, nil
 }
+
+func TestPipeline_Without(t *testing.T) {
+	p := NewPipeline(
+		stubScanner{name: "a", result: ScanResult{Verdict: VerdictModify, Content: "from a"}},
+		stubScanner{name: "b", result: ScanResult{Verdict: VerdictModify, Content: "from b"}},
+		stubScanner{name: "c", result: ScanResult{Verdict: VerdictPass}},
+	)
+
+	res, err := p.Without("b").Process(context.Background(), "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.FinalContent != "from a" {
+		t.Errorf("content = %q, want %q", res.FinalContent, "from a")
+	}
+	if len(res.ScanResults) != 2 {
+		t.Errorf("scan results count = %d, want 2", len(res.ScanResults))
+	}
+	if len(p.scanners) != 3 {
+		t.Errorf("original pipeline modified: %d scanners", len(p.scanners))
+	}
+}
+
+func TestPipeline_WithObserver(t *testing.T) {
+	var observed []Verdict
+	p := NewPipeline(
+		stubScanner{name: "a", result: ScanResult{Verdict: VerdictModify, Content: "from a"}},
+		stubScanner{name: "b", result: ScanResult{Verdict: VerdictBlock, Content: "from b"}},
+		stubScanner{name: "c", result: ScanResult{Verdict: VerdictPass}},
+	).WithObserver(func(_ context.Context, sr ScanResult) {
+		observed = append(observed, sr.Verdict)
+	})
+
+	if _, err := p.Process(context.Background(), "input"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Verdict{VerdictModify, VerdictBlock}
+	if !slices.Equal(observed, want) {
+		t.Errorf("observed = %v, want %v", observed, want)
+	}
+
+	observed = nil
+	if _, err := p.Without("b").Process(context.Background(), "input"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(observed) != 2 {
+		t.Errorf("derived pipeline observed %d scans, want 2", len(observed))
+	}
+}
+
+func TestPipeline_ScannerSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "call")
+	p := NewPipeline(
+		stubScanner{name: "a", result: ScanResult{Verdict: VerdictModify, Content: "from a"}},
+		stubScanner{name: "b", err: errors.New("boom")},
+	)
+	if _, err := p.Process(ctx, "input"); err == nil {
+		t.Fatal("expected error")
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	a, b := spans[0], spans[1]
+	if a.Name != "scan a" || b.Name != "scan b" {
+		t.Errorf("span names = %q, %q", a.Name, b.Name)
+	}
+	if a.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("scan span should be a child of the caller's span")
+	}
+	if b.Status.Code != codes.Error {
+		t.Errorf("failed scan status = %v, want Error", b.Status.Code)
+	}
+}
+
+func TestPipeline_ReportOnly(t *testing.T) {
+	p := NewPipeline(
+		stubScanner{name: "a", result: ScanResult{Verdict: VerdictModify, Content: "from a", ScannerName: "a"}},
+		stubScanner{name: "b", result: ScanResult{Verdict: VerdictBlock, Threats: []string{"bad"}, ScannerName: "b"}},
+		stubScanner{name: "c", result: ScanResult{Verdict: VerdictModify, Content: "from c", ScannerName: "c"}},
+	).ReportOnly("a", "b")
+
+	res, err := p.Process(context.Background(), "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.FinalContent != "from c" || res.FinalVerdict != VerdictModify {
+		t.Errorf("got %v %q, want only c applied", res.FinalVerdict, res.FinalContent)
+	}
+	if len(res.AllThreats) != 0 {
+		t.Errorf("reported threats should not be in AllThreats: %v", res.AllThreats)
+	}
+	if len(res.ScanResults) != 3 {
+		t.Fatalf("scan results count = %d, want 3", len(res.ScanResults))
+	}
+	b := res.ScanResults[1]
+	if !b.Reported || b.Verdict != VerdictBlock || len(b.Threats) != 1 {
+		t.Errorf("reported scan = %+v, want block verdict and threats recorded", b)
+	}
+	if res.ScanResults[2].Reported {
+		t.Error("enforced scanner marked as reported")
+	}
+}
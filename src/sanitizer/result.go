@@ -32,9 +32,14 @@ type ScanResult struct {
 	Content     string   // original or modified content
 	Threats     []string // human-readable threat descriptions
 	ScannerName string
+	// Reported is set when the scanner runs in report mode: the verdict is
+	// recorded but was not applied by the pipeline.
+	Reported bool
 }
 
 // PipelineResult aggregates results from all scanners in a pipeline.
+// FinalVerdict, FinalContent and AllThreats only reflect enforced scanners;
+// the findings of report-mode scanners are in ScanResults.
 type PipelineResult struct {
 	FinalVerdict Verdict
 	FinalContent string
@@ -0,0 +1,195 @@
+package sanitizer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// SecretRule describes one kind of credential to detect. If Pattern has a
+// capture group named "secret", only that group is redacted; otherwise the
+// whole match is. When MinEntropy is non-zero, matches whose redacted part
+// has a lower Shannon entropy (bits per character) are ignored, which keeps
+// generic rules from firing on placeholders like "password=changeme".
+type SecretRule struct {
+	Name       string
+	Pattern    string
+	MinEntropy float64
+}
+
+// builtInSecretRules cover common credential formats. Provider-specific
+// rules come first so that a key is labelled with its most precise type.
+var builtInSecretRules = []SecretRule{
+	{Name: "private_key", Pattern: `-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`},
+	{Name: "aws_access_key", Pattern: `\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`},
+	{Name: "aws_secret_key", Pattern: `(?i)aws.{0,20}?(?:secret|private).{0,20}?['"]?\s*[:=]\s*['"]?(?P<secret>[A-Za-z0-9/+]{40})\b`},
+	{Name: "github_token", Pattern: `\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}\b`},
+	{Name: "github_token", Pattern: `\bgithub_pat_[A-Za-z0-9_]{22,255}\b`},
+	{Name: "gitlab_token", Pattern: `\bglpat-[A-Za-z0-9_-]{20,}\b`},
+	{Name: "slack_token", Pattern: `\bxox[abposr]-[A-Za-z0-9-]{10,}\b`},
+	{Name: "slack_webhook", Pattern: `https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+`},
+	{Name: "stripe_key", Pattern: `\b(?:sk|rk)_(?:live|test)_[A-Za-z0-9]{16,}\b`},
+	{Name: "google_api_key", Pattern: `\bAIza[0-9A-Za-z_-]{35}\b`},
+	{Name: "anthropic_api_key", Pattern: `\bsk-ant-[A-Za-z0-9_-]{20,}`},
+	{Name: "openai_api_key", Pattern: `\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}`},
+	{Name: "jwt", Pattern: `\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`},
+	{
+		Name:       "generic_secret",
+		Pattern:    `(?i)\b(?:api[_-]?key|secret|token|password|passwd|pwd|access[_-]?key|client[_-]?secret|auth)["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=_.-]{12,})`,
+		MinEntropy: 3.0,
+	},
+	{
+		Name:       "high_entropy",
+		Pattern:    `\b(?P<secret>[A-Za-z0-9+_-]{32,})={0,2}`,
+		MinEntropy: 4.5,
+	},
+}
+
+type secretRule struct {
+	name       string
+	re         *regexp.Regexp
+	group      int // index of the "secret" group, or 0 for the whole match
+	minEntropy float64
+}
+
+// SecretsScanner detects leaked credentials and replaces each one with a
+// typed placeholder such as [REDACTED:aws_access_key]. When Block is set it
+// rejects content containing a secret instead of redacting it, which is
+// what the argument pipeline wants: a secret in an outbound tool call is a
+// leak, not something to quietly rewrite.
+type SecretsScanner struct {
+	Block bool
+
+	rules []secretRule
+}
+
+// NewSecretsScanner builds a scanner from the given configuration.
+// If disableBuiltIn is false, built-in rules are included.
+// customRules are always appended.
+func NewSecretsScanner(disableBuiltIn bool, customRules []SecretRule) (*SecretsScanner, error) {
+	var sources []SecretRule
+
+	if !disableBuiltIn {
+		sources = append(sources, builtInSecretRules...)
+	}
+	sources = append(sources, customRules...)
+
+	compiled := make([]secretRule, 0, len(sources))
+	for _, r := range sources {
+		if r.Name == "" {
+			return nil, fmt.Errorf("secret rule %q: name is required", r.Pattern)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling secret rule %s: %w", r.Name, err)
+		}
+		compiled = append(compiled, secretRule{
+			name:       r.Name,
+			re:         re,
+			group:      max(re.SubexpIndex("secret"), 0),
+			minEntropy: r.MinEntropy,
+		})
+	}
+
+	return &SecretsScanner{rules: compiled}, nil
+}
+
+func (s *SecretsScanner) Name() string { return "secrets" }
+
+func (s *SecretsScanner) Scan(_ context.Context, content string) (ScanResult, error) {
+	current := content
+	var found []string
+
+	for _, rule := range s.rules {
+		redacted, n := rule.redact(current)
+		if n == 0 {
+			continue
+		}
+		current = redacted
+		found = append(found, rule.name)
+	}
+
+	if len(found) == 0 {
+		return ScanResult{
+			Verdict:     VerdictPass,
+			Content:     content,
+			ScannerName: s.Name(),
+		}, nil
+	}
+
+	threats := make([]string, 0, len(found))
+	for _, name := range found {
+		threats = append(threats, fmt.Sprintf("secret detected: %s", name))
+	}
+
+	if s.Block {
+		return ScanResult{
+			Verdict:     VerdictBlock,
+			Content:     content,
+			Threats:     threats,
+			ScannerName: s.Name(),
+		}, nil
+	}
+
+	return ScanResult{
+		Verdict:     VerdictModify,
+		Content:     current,
+		Threats:     threats,
+		ScannerName: s.Name(),
+	}, nil
+}
+
+// redact replaces every qualifying match of the rule in content and
+// returns the new content and the number of replacements.
+func (r secretRule) redact(content string) (string, int) {
+	matches := r.re.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content, 0
+	}
+
+	placeholder := "[REDACTED:" + r.name + "]"
+
+	var b strings.Builder
+	last, n := 0, 0
+	for _, m := range matches {
+		start, end := m[2*r.group], m[2*r.group+1]
+		if start < 0 || start < last {
+			continue
+		}
+		if r.minEntropy > 0 && shannonEntropy(content[start:end]) < r.minEntropy {
+			continue
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(placeholder)
+		last = end
+		n++
+	}
+	if n == 0 {
+		return content, 0
+	}
+	b.WriteString(content[last:])
+	return b.String(), n
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,118 @@
+// Package tracing sets up OpenTelemetry tracing for the gateway and carries
+// W3C trace context through MCP request metadata.
+//
+// Spans are started from the tracer provider of the span already in the
+// context where possible, so that packages such as the sanitizer emit child
+// spans only when the call they belong to is being traced.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ScopeName is the instrumentation scope of the gateway's spans.
+const ScopeName = "github.com/Easy-Infra-Ltd/easy-mcp-gateway"
+
+// NewProvider builds a tracer provider that exports spans as configured.
+// The caller must Shutdown the provider to flush buffered spans.
+func NewProvider(ctx context.Context, cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Exporter {
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case config.TracingExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s exporter: %w", cfg.Exporter, err)
+	}
+
+	ratio := 1.0
+	if cfg.SampleRatio != nil {
+		ratio = *cfg.SampleRatio
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("easy-mcp-gateway"),
+		semconv.ServiceVersion(transport.Version),
+	)
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	), nil
+}
+
+// propagator reads and writes the W3C traceparent and tracestate keys.
+var propagator = propagation.TraceContext{}
+
+// Extract returns ctx carrying the remote trace context found in the _meta
+// of an MCP request, if any.
+func Extract(ctx context.Context, meta mcp.Meta) context.Context {
+	if len(meta) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, metaCarrier(meta))
+}
+
+// Inject adds the trace context of ctx to the _meta of an outgoing MCP
+// request. It returns meta, allocated if nil and there is a context to
+// propagate.
+func Inject(ctx context.Context, meta mcp.Meta) mcp.Meta {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return meta
+	}
+	if meta == nil {
+		meta = make(mcp.Meta, len(carrier))
+	}
+	for k, v := range carrier {
+		meta[k] = v
+	}
+	return meta
+}
+
+// metaCarrier adapts MCP _meta to a read-only propagation.TextMapCarrier.
+// Non-string values are ignored.
+type metaCarrier mcp.Meta
+
+func (c metaCarrier) Get(key string) string {
+	s, _ := c[key].(string)
+	return s
+}
+
+func (c metaCarrier) Set(string, string) {}
+
+func (c metaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
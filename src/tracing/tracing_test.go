@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestExtractInject_roundTrip(t *testing.T) {
+	ctx := Extract(context.Background(), mcp.Meta{
+		"traceparent":   testTraceparent,
+		"progressToken": 7,
+	})
+
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsRemote() {
+		t.Fatalf("expected a remote span context, got %+v", sc)
+	}
+	if got := sc.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s", got)
+	}
+
+	meta := Inject(ctx, nil)
+	if meta["traceparent"] != testTraceparent {
+		t.Errorf("traceparent = %v, want %s", meta["traceparent"], testTraceparent)
+	}
+}
+
+func TestExtract_noTraceContext(t *testing.T) {
+	ctx := Extract(context.Background(), mcp.Meta{"traceparent": 42})
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		t.Error("expected no span context for a non-string traceparent")
+	}
+}
+
+func TestInject_nothingToPropagate(t *testing.T) {
+	if meta := Inject(context.Background(), nil); meta != nil {
+		t.Errorf("expected nil meta, got %v", meta)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	for _, exporter := range []string{config.TracingExporterStdout, config.TracingExporterOTLP} {
+		t.Run(exporter, func(t *testing.T) {
+			tp, err := NewProvider(context.Background(), config.TracingConfig{
+				Exporter: exporter,
+				Endpoint: "localhost:4318",
+				Insecure: true,
+			})
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			if err := tp.Shutdown(context.Background()); err != nil {
+				t.Errorf("Shutdown: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewProvider_unsupportedExporter(t *testing.T) {
+	if _, err := NewProvider(context.Background(), config.TracingConfig{Exporter: "zipkin"}); err == nil {
+		t.Error("expected error for unsupported exporter")
+	}
+}
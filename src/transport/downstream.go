@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/metrics"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -31,16 +33,58 @@ type TransportFactory func(config.DownstreamConfig) (mcp.Transport, error)
 type DownstreamManager struct {
 	mu               sync.RWMutex
 	conns            map[string]*DownstreamConn
+	names            []string // configured server names, in config order
 	logger           *slog.Logger
 	transportFactory TransportFactory
 
+	// changed holds the servers whose tools, resources or prompts may have
+	// changed since the last TakeChanged, in the order they were first
+	// reported. Guarded by changedMu.
+	changedMu sync.Mutex
+	changed   []string
+	// toolsChanged is signalled whenever a server is added to changed. See
+	// ToolsChanged.
+	toolsChanged chan struct{}
+
 	// cancelHealthCheck stops the background health check goroutine.
 	cancelHealthCheck context.CancelFunc
+
+	// metrics records health check connection attempts; nil when disabled.
+	// Guarded by mu.
+	metrics *metrics.Metrics
+
+	// status tracks the health of every configured server. Guarded by mu.
+	status map[string]*ServerStatus
+	// lastHealthCheck is when the health check last completed a round, or
+	// when the manager started. Guarded by mu.
+	lastHealthCheck time.Time
+}
+
+// ServerStatus describes the health of a configured downstream server as
+// seen by the connection attempts and health checks.
+type ServerStatus struct {
+	Name      string `json:"name"`
+	Transport string `json:"transport"`
+	Connected bool   `json:"connected"`
+	// ConnectedSince is when the current session was established; zero
+	// while the server is pending.
+	ConnectedSince time.Time `json:"connectedSince,omitzero"`
+	// LastPing is when the health check last pinged the server
+	// successfully.
+	LastPing time.Time `json:"lastPing,omitzero"`
+	// LastError is the most recent connection or ping failure, kept after
+	// the server recovers.
+	LastError   string    `json:"lastError,omitempty"`
+	LastErrorAt time.Time `json:"lastErrorAt,omitzero"`
+	// Reconnects counts connection attempts made by the health check,
+	// successful or not.
+	Reconnects int `json:"reconnects"`
 }
 
 // NewDownstreamManager creates a manager and connects to all configured
 // downstream servers. Connections that fail are logged but do not prevent
-// startup — they will be retried by health checks.
+// startup, even if every server fails — they will be retried by health
+// checks, and readiness reports the gateway as not ready meanwhile.
 //
 // If transportFactory is nil, the default factory (stdio/HTTP) is used.
 func NewDownstreamManager(ctx context.Context, downstream []config.DownstreamConfig, logger *slog.Logger, transportFactory TransportFactory) (*DownstreamManager, error) {
@@ -49,24 +93,28 @@ func NewDownstreamManager(ctx context.Context, downstream []config.DownstreamCon
 	}
 	dm := &DownstreamManager{
 		conns:            make(map[string]*DownstreamConn, len(downstream)),
+		names:            make([]string, 0, len(downstream)),
 		logger:           logger.With("area", "downstream"),
 		transportFactory: transportFactory,
+		toolsChanged:     make(chan struct{}, 1),
+		status:           make(map[string]*ServerStatus, len(downstream)),
 	}
 
 	for _, ds := range downstream {
+		dm.names = append(dm.names, ds.Name)
+		dm.status[ds.Name] = &ServerStatus{Name: ds.Name, Transport: ds.Transport}
+
 		conn, err := dm.connect(ctx, ds)
+		dm.recordConnect(ds.Name, err)
 		if err != nil {
-			dm.logger.Error("failed to connect", "server", ds.Name, "err", err)
+			dm.logger.Error("failed to connect, server pending", "server", ds.Name, "err", err)
 			continue
 		}
 		dm.conns[ds.Name] = conn
 		dm.logger.Info("connected", "server", ds.Name, "transport", ds.Transport)
 	}
 
-	if len(dm.conns) == 0 {
-		return nil, fmt.Errorf("failed to connect to any downstream servers")
-	}
-
+	dm.lastHealthCheck = time.Now()
 	hctx, cancel := context.WithCancel(ctx)
 	dm.cancelHealthCheck = cancel
 	go dm.healthCheckLoop(hctx, downstream)
@@ -97,6 +145,148 @@ func (dm *DownstreamManager) Conns() map[string]*DownstreamConn {
 	return out
 }
 
+// ConnsInOrder returns a snapshot of all active connections, in config
+// order.
+func (dm *DownstreamManager) ConnsInOrder() []*DownstreamConn {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	out := make([]*DownstreamConn, 0, len(dm.conns))
+	for _, name := range dm.names {
+		if conn, ok := dm.conns[name]; ok {
+			out = append(out, conn)
+		}
+	}
+	return out
+}
+
+// Connected reports, for each configured downstream server, whether it is
+// currently connected.
+func (dm *DownstreamManager) Connected() map[string]bool {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	out := make(map[string]bool, len(dm.names))
+	for _, name := range dm.names {
+		_, ok := dm.conns[name]
+		out[name] = ok
+	}
+	return out
+}
+
+// Status returns the status of every configured downstream server, in
+// config order.
+func (dm *DownstreamManager) Status() []ServerStatus {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	out := make([]ServerStatus, 0, len(dm.names))
+	for _, name := range dm.names {
+		out = append(out, *dm.status[name])
+	}
+	return out
+}
+
+// livenessTimeout is how long the health check may go without completing a
+// round before Alive reports false.
+const livenessTimeout = 3 * healthCheckInterval
+
+// Alive reports whether the health check loop is making progress, i.e. has
+// completed a round within the last three intervals. A round that hangs,
+// for example on a downstream that never answers, makes the manager dead.
+func (dm *DownstreamManager) Alive() bool {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return time.Since(dm.lastHealthCheck) < livenessTimeout
+}
+
+// recordConnect updates the status of name after a connection attempt.
+func (dm *DownstreamManager) recordConnect(name string, err error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	st := dm.status[name]
+	now := time.Now()
+	if err != nil {
+		st.Connected = false
+		st.ConnectedSince = time.Time{}
+		st.LastError, st.LastErrorAt = err.Error(), now
+		return
+	}
+	st.Connected = true
+	st.ConnectedSince = now
+}
+
+// recordPing updates the status of name after a health check ping.
+func (dm *DownstreamManager) recordPing(name string, err error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	st := dm.status[name]
+	now := time.Now()
+	if err != nil {
+		st.LastError, st.LastErrorAt = err.Error(), now
+		return
+	}
+	st.LastPing = now
+}
+
+// SetMetrics records health check connection attempts in m and exposes the
+// connection state of each server.
+func (dm *DownstreamManager) SetMetrics(m *metrics.Metrics) {
+	dm.mu.Lock()
+	dm.metrics = m
+	dm.mu.Unlock()
+	m.TrackConnections(dm.Connected)
+}
+
+// Pending returns the names of configured downstream servers that are not
+// currently connected, in config order. Pending servers are retried by the
+// health check.
+func (dm *DownstreamManager) Pending() []string {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	var pending []string
+	for _, name := range dm.names {
+		if _, ok := dm.conns[name]; !ok {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
+
+// ToolsChanged returns a channel that is signalled whenever the tools,
+// resources or prompts of a downstream server may have changed: when the
+// server sends a list_changed notification, and after it is reconnected by
+// the health check (including the first connection of a pending server).
+// Call TakeChanged to get the servers concerned.
+//
+// Events are coalesced per server rather than queued, so none are lost
+// however slowly they are consumed: a burst of notifications from one server
+// yields a single entry, and a single signal may stand for several servers.
+func (dm *DownstreamManager) ToolsChanged() <-chan struct{} {
+	return dm.toolsChanged
+}
+
+// TakeChanged returns the servers reported since the last call, in the order
+// they were first reported, and clears them.
+func (dm *DownstreamManager) TakeChanged() []string {
+	dm.changedMu.Lock()
+	defer dm.changedMu.Unlock()
+	changed := dm.changed
+	dm.changed = nil
+	return changed
+}
+
+func (dm *DownstreamManager) signalToolsChanged(name string) {
+	dm.changedMu.Lock()
+	if !slices.Contains(dm.changed, name) {
+		dm.changed = append(dm.changed, name)
+	}
+	dm.changedMu.Unlock()
+
+	select {
+	case dm.toolsChanged <- struct{}{}:
+	default:
+		// A signal is already pending; it covers this server too.
+	}
+}
+
 // Close terminates all downstream connections and stops health checks.
 func (dm *DownstreamManager) Close() {
 	if dm.cancelHealthCheck != nil {
@@ -119,7 +309,21 @@ func (dm *DownstreamManager) connect(ctx context.Context, ds config.DownstreamCo
 			Name:    "easy-mcp-gateway",
 			Version: Version,
 		},
-		&mcp.ClientOptions{Logger: dm.logger},
+		&mcp.ClientOptions{
+			Logger: dm.logger,
+			ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+				dm.logger.Info("tool list changed", "server", ds.Name)
+				dm.signalToolsChanged(ds.Name)
+			},
+			ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+				dm.logger.Info("resource list changed", "server", ds.Name)
+				dm.signalToolsChanged(ds.Name)
+			},
+			PromptListChangedHandler: func(context.Context, *mcp.PromptListChangedRequest) {
+				dm.logger.Info("prompt list changed", "server", ds.Name)
+				dm.signalToolsChanged(ds.Name)
+			},
+		},
 	)
 
 	transport, err := dm.transportFactory(ds)
@@ -186,6 +390,10 @@ func (dm *DownstreamManager) checkAndReconnect(ctx context.Context, cfgs map[str
 		return
 	}
 
+	dm.mu.RLock()
+	m := dm.metrics
+	dm.mu.RUnlock()
+
 	for name, cfg := range cfgs {
 		dm.mu.RLock()
 		conn, connected := dm.conns[name]
@@ -196,6 +404,7 @@ func (dm *DownstreamManager) checkAndReconnect(ctx context.Context, cfgs map[str
 			pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			err := conn.Session.Ping(pingCtx, &mcp.PingParams{})
 			cancel()
+			dm.recordPing(name, err)
 			if err == nil {
 				continue
 			}
@@ -205,8 +414,17 @@ func (dm *DownstreamManager) checkAndReconnect(ctx context.Context, cfgs map[str
 
 		// Attempt reconnection.
 		newConn, err := dm.connect(ctx, cfg)
+		m.Reconnect(name, err == nil)
+		dm.mu.Lock()
+		dm.status[name].Reconnects++
+		dm.mu.Unlock()
+		dm.recordConnect(name, err)
 		if err != nil {
-			dm.logger.Error("reconnect failed", "server", name, "err", err)
+			if connected {
+				dm.logger.Error("reconnect failed, server pending", "server", name, "err", err)
+			} else {
+				dm.logger.Warn("server still pending", "server", name, "err", err)
+			}
 			dm.mu.Lock()
 			delete(dm.conns, name)
 			dm.mu.Unlock()
@@ -216,6 +434,17 @@ func (dm *DownstreamManager) checkAndReconnect(ctx context.Context, cfgs map[str
 		dm.mu.Lock()
 		dm.conns[name] = newConn
 		dm.mu.Unlock()
-		dm.logger.Info("reconnected", "server", name)
+		if connected {
+			dm.logger.Info("reconnected", "server", name)
+		} else {
+			dm.logger.Info("connected pending server", "server", name)
+		}
+
+		// The new session may expose a different tool set.
+		dm.signalToolsChanged(name)
 	}
+
+	dm.mu.Lock()
+	dm.lastHealthCheck = time.Now()
+	dm.mu.Unlock()
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -102,11 +104,16 @@ func TestNewDownstreamManager_allFail(t *testing.T) {
 		return nil, errTestConnect
 	}
 
-	_, err := NewDownstreamManager(ctx, []config.DownstreamConfig{
+	dm, err := NewDownstreamManager(ctx, []config.DownstreamConfig{
 		{Name: "bad", Transport: config.TransportStdio, Command: []string{"dummy"}},
 	}, testLogger(), factory)
-	if err == nil {
-		t.Fatal("expected error when all connections fail")
+	if err != nil {
+		t.Fatalf("should start with every server pending: %v", err)
+	}
+	defer dm.Close()
+
+	if pending := dm.Pending(); !slices.Equal(pending, []string{"bad"}) {
+		t.Errorf("expected [bad] pending, got %v", pending)
 	}
 }
 
@@ -140,6 +147,76 @@ func TestNewDownstreamManager_partialFailure(t *testing.T) {
 	if dm.Session("bad") != nil {
 		t.Error("expected nil session for bad")
 	}
+
+	if pending := dm.Pending(); len(pending) != 1 || pending[0] != "bad" {
+		t.Errorf("expected [bad] pending, got %v", pending)
+	}
+
+	want := map[string]bool{"good": true, "bad": false}
+	if got := dm.Connected(); !maps.Equal(got, want) {
+		t.Errorf("Connected() = %v, want %v", got, want)
+	}
+
+	st := dm.Status()
+	if len(st) != 2 || st[0].Name != "good" || st[1].Name != "bad" {
+		t.Fatalf("Status() = %+v, want good then bad", st)
+	}
+	if st[1].Connected || st[1].LastError == "" {
+		t.Errorf("expected bad pending with an error, got %+v", st[1])
+	}
+}
+
+func TestHealthCheck_connectsPendingServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	lateReady := false
+
+	factory := func(ds config.DownstreamConfig) (mcp.Transport, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ds.Name == "late" && !lateReady {
+			return nil, errTestConnect
+		}
+		return testServer(t, ctx), nil
+	}
+
+	cfgs := []config.DownstreamConfig{
+		{Name: "early", Transport: config.TransportStdio, Command: []string{"dummy"}},
+		{Name: "late", Transport: config.TransportStdio, Command: []string{"dummy"}},
+	}
+	dm, err := NewDownstreamManager(ctx, cfgs, testLogger(), factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dm.Close()
+
+	if pending := dm.Pending(); len(pending) != 1 || pending[0] != "late" {
+		t.Fatalf("expected [late] pending, got %v", pending)
+	}
+
+	mu.Lock()
+	lateReady = true
+	mu.Unlock()
+
+	dm.checkAndReconnect(ctx, map[string]config.DownstreamConfig{"late": cfgs[1]})
+
+	if dm.Session("late") == nil {
+		t.Fatal("expected session for late after health check")
+	}
+	if pending := dm.Pending(); len(pending) != 0 {
+		t.Errorf("expected no pending servers, got %v", pending)
+	}
+
+	select {
+	case <-dm.ToolsChanged():
+		if changed := dm.TakeChanged(); !slices.Equal(changed, []string{"late"}) {
+			t.Errorf("expected tools changed event for late, got %v", changed)
+		}
+	default:
+		t.Error("expected tools changed event for newly connected server")
+	}
 }
 
 func TestSession_unknownName(t *testing.T) {
@@ -279,6 +356,88 @@ func TestHealthCheck_reconnects(t *testing.T) {
 	if newSession == conn.Session {
 		t.Error("expected a different session after reconnection")
 	}
+
+	st := dm.Status()
+	if len(st) != 1 || st[0].Name != "s" {
+		t.Fatalf("Status() = %+v, want one entry for s", st)
+	}
+	if !st[0].Connected || st[0].ConnectedSince.IsZero() {
+		t.Errorf("expected s connected, got %+v", st[0])
+	}
+	if st[0].Reconnects != 1 {
+		t.Errorf("Reconnects = %d, want 1", st[0].Reconnects)
+	}
+	if st[0].LastError == "" {
+		t.Error("expected failed ping recorded as LastError")
+	}
+
+	select {
+	case <-dm.ToolsChanged():
+		if changed := dm.TakeChanged(); !slices.Equal(changed, []string{"s"}) {
+			t.Errorf("expected tools changed event for s, got %v", changed)
+		}
+	default:
+		t.Error("expected tools changed event after reconnection")
+	}
+}
+
+func TestToolsChanged_listChangedNotification(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "0.0.1"}, nil)
+	srv.AddTool(&mcp.Tool{
+		Name:        "echo",
+		InputSchema: map[string]any{"type": "object"},
+	}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	srvTransport, clientTransport := mcp.NewInMemoryTransports()
+	go func() {
+		_ = srv.Run(ctx, srvTransport)
+	}()
+
+	dm, err := NewDownstreamManager(ctx, []config.DownstreamConfig{
+		{Name: "s", Transport: config.TransportStdio, Command: []string{"dummy"}},
+	}, testLogger(), singleTransportFactory(clientTransport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dm.Close()
+
+	srv.RemoveTools("echo")
+
+	select {
+	case <-dm.ToolsChanged():
+		if changed := dm.TakeChanged(); !slices.Equal(changed, []string{"s"}) {
+			t.Errorf("expected tools changed event for s, got %v", changed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for tools changed event")
+	}
+}
+
+func TestToolsChanged_coalescesWithoutDropping(t *testing.T) {
+	dm := &DownstreamManager{toolsChanged: make(chan struct{}, 1)}
+
+	// Far more events than any buffer would hold, with nobody reading.
+	for i := range 1000 {
+		dm.signalToolsChanged(fmt.Sprintf("s%d", i%3))
+	}
+	dm.signalToolsChanged("pending")
+
+	select {
+	case <-dm.ToolsChanged():
+	default:
+		t.Fatal("expected a pending signal")
+	}
+	want := []string{"s0", "s1", "s2", "pending"}
+	if changed := dm.TakeChanged(); !slices.Equal(changed, want) {
+		t.Errorf("TakeChanged() = %v, want %v", changed, want)
+	}
+	if changed := dm.TakeChanged(); len(changed) != 0 {
+		t.Errorf("expected changes to be cleared, got %v", changed)
+	}
 }
 
 // --- helpers ---
@@ -303,3 +462,32 @@ func namedTransportFactory(m map[string]mcp.Transport) TransportFactory {
 		return t, nil
 	}
 }
+
+func TestAlive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dm, err := NewDownstreamManager(ctx, []config.DownstreamConfig{
+		{Name: "s", Transport: config.TransportStdio, Command: []string{"dummy"}},
+	}, testLogger(), singleTransportFactory(testServer(t, ctx)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dm.Close()
+
+	if !dm.Alive() {
+		t.Error("expected manager alive after start")
+	}
+
+	dm.mu.Lock()
+	dm.lastHealthCheck = time.Now().Add(-livenessTimeout)
+	dm.mu.Unlock()
+	if dm.Alive() {
+		t.Error("expected manager dead when the health check has stalled")
+	}
+
+	dm.checkAndReconnect(ctx, nil)
+	if !dm.Alive() {
+		t.Error("expected manager alive after a health check round")
+	}
+}
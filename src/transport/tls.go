@@ -0,0 +1,146 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+)
+
+// tlsCheckInterval limits how often the certificate files are checked for
+// changes. Checks happen during handshakes, so an idle listener does no
+// work.
+const tlsCheckInterval = 5 * time.Second
+
+// tlsReloader serves the upstream TLS config, reloading the certificate,
+// key and client CA bundle when their files change. Connections already
+// established keep the config they were made with.
+type tlsReloader struct {
+	cfg    config.TLSConfig
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	current   *tls.Config
+	modTimes  []time.Time
+	checkedAt time.Time
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+func newTLSReloader(cfg config.TLSConfig, logger *slog.Logger) (*tlsReloader, error) {
+	r := &tlsReloader{cfg: cfg, logger: logger, now: time.Now}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Config returns the listener config. It defers to the current config,
+// reloaded if due, on every handshake.
+func (r *tlsReloader) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.get(), nil
+		},
+	}
+}
+
+func (r *tlsReloader) get() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.now().Sub(r.checkedAt) >= tlsCheckInterval {
+		r.checkedAt = r.now()
+		if r.changed() {
+			// A failed reload, e.g. while the files are half written, keeps
+			// the previous config and is retried at the next check.
+			if err := r.loadLocked(); err != nil {
+				r.logger.Error("reloading TLS certificate, keeping previous", "err", err)
+			} else {
+				r.logger.Info("reloaded TLS certificate", "cert", r.cfg.CertFile)
+			}
+		}
+	}
+	return r.current
+}
+
+func (r *tlsReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkedAt = r.now()
+	return r.loadLocked()
+}
+
+// loadLocked reads the files and builds a new config. Must be called with
+// r.mu held.
+func (r *tlsReloader) loadLocked() error {
+	modTimes := r.modTimesNow()
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	c := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("loading client CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("loading client CA bundle: no certificates in %s", r.cfg.ClientCAFile)
+		}
+		// Certificates are verified when presented but not required, so
+		// that the probes stay reachable; the MCP endpoint's auth decides
+		// whether a request needs one.
+		c.ClientCAs = pool
+		c.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	r.current = c
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *tlsReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// modTimesNow returns the modification time of each file, zero for files
+// that cannot be read.
+func (r *tlsReloader) modTimesNow() []time.Time {
+	files := r.files()
+	times := make([]time.Time, len(files))
+	for i, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			times[i] = info.ModTime()
+		}
+	}
+	return times
+}
+
+func (r *tlsReloader) changed() bool {
+	now := r.modTimesNow()
+	for i := range now {
+		if !now[i].Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Easy-Infra-Ltd/easy-mcp-gateway/src/config"
+)
+
+// testCert is a certificate and key issued by parent, or self-signed when
+// parent is nil.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write writes the certificate and key as PEM files.
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile == "" {
+		return
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// serveTLS serves a handler reporting the verified client certificate's
+// common name behind the reloader's config, returning its address.
+func serveTLS(t *testing.T, r *tlsReloader) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) == 0 {
+			w.Write([]byte("anonymous"))
+			return
+		}
+		w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}), ErrorLog: log.New(io.Discard, "", 0)}
+	go srv.Serve(tls.NewListener(ln, r.Config()))
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+// handshake connects to addr trusting ca and returns the server
+// certificate's common name.
+func handshake(t *testing.T, addr string, ca *testCert) (string, error) {
+	t.Helper()
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestTLSReloader_clientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	cfg := config.TLSConfig{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	newTestCert(t, "gateway", ca).write(t, cfg.CertFile, cfg.KeyFile)
+	ca.write(t, cfg.ClientCAFile, "")
+
+	r, err := newTLSReloader(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("newTLSReloader: %v", err)
+	}
+	addr := serveTLS(t, r)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	get := func(client *testCert) (string, error) {
+		tr := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+		if client != nil {
+			// Sent even when the server does not list its issuer.
+			cert := client.tlsCertificate()
+			tr.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &cert, nil
+			}
+		}
+		defer tr.CloseIdleConnections()
+		resp, err := (&http.Client{Transport: tr}).Get("https://" + addr)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		return string(body[:n]), nil
+	}
+
+	if got, err := get(newTestCert(t, "billing-agent", ca)); err != nil || got != "billing-agent" {
+		t.Errorf("trusted client = %q, %v; want billing-agent", got, err)
+	}
+	if got, err := get(nil); err != nil || got != "anonymous" {
+		t.Errorf("no client certificate = %q, %v; want anonymous", got, err)
+	}
+	if _, err := get(newTestCert(t, "mallory", nil)); err == nil {
+		t.Error("expected handshake failure for an untrusted client certificate")
+	}
+}
+
+func TestTLSReloader_reloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	cfg := config.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	newTestCert(t, "first", ca).write(t, cfg.CertFile, cfg.KeyFile)
+
+	r, err := newTLSReloader(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("newTLSReloader: %v", err)
+	}
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	// advance moves the reloader's clock under its lock, as handshakes
+	// read it concurrently.
+	advance := func() {
+		r.mu.Lock()
+		now = now.Add(tlsCheckInterval)
+		r.mu.Unlock()
+	}
+	addr := serveTLS(t, r)
+
+	if got, err := handshake(t, addr, ca); err != nil || got != "first" {
+		t.Fatalf("initial certificate = %q, %v; want first", got, err)
+	}
+
+	// An established connection survives the rotation.
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	newTestCert(t, "second", ca).write(t, cfg.CertFile, cfg.KeyFile)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(cfg.CertFile, future, future)
+
+	if got, _ := handshake(t, addr, ca); got != "first" {
+		t.Errorf("certificate before the check interval = %q, want first", got)
+	}
+	advance()
+	if got, err := handshake(t, addr, ca); err != nil || got != "second" {
+		t.Errorf("certificate after rotation = %q, %v; want second", got, err)
+	}
+
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")); err != nil {
+		t.Fatalf("established connection dropped: %v", err)
+	}
+	if resp, err := http.ReadResponse(bufio.NewReader(conn), nil); err != nil {
+		t.Errorf("established connection dropped: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	// A broken file keeps the previous certificate.
+	os.WriteFile(cfg.KeyFile, []byte("garbage"), 0o600)
+	os.Chtimes(cfg.KeyFile, future.Add(time.Minute), future.Add(time.Minute))
+	advance()
+	if got, err := handshake(t, addr, ca); err != nil || got != "second" {
+		t.Errorf("certificate after failed reload = %q, %v; want second", got, err)
+	}
+}
+
+func TestNewTLSReloader_errors(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	if _, err := newTLSReloader(cfg, testLogger()); err == nil {
+		t.Error("expected error for missing certificate files")
+	}
+
+	newTestCert(t, "gateway", nil).write(t, cfg.CertFile, cfg.KeyFile)
+	cfg.ClientCAFile = filepath.Join(dir, "ca.pem")
+	os.WriteFile(cfg.ClientCAFile, []byte("not pem"), 0o600)
+	if _, err := newTLSReloader(cfg, testLogger()); err == nil {
+		t.Error("expected error for a client CA bundle without certificates")
+	}
+}
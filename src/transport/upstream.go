@@ -2,6 +2,7 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net"
@@ -18,6 +19,13 @@ type Upstream struct {
 	Server *mcp.Server
 	cfg    config.UpstreamConfig
 	logger *slog.Logger
+
+	// routes are extra handlers served alongside the MCP endpoint on the
+	// HTTP transport.
+	routes map[string]http.Handler
+	// middleware wraps the MCP endpoint on the HTTP transport, outermost
+	// last.
+	middleware []func(http.Handler) http.Handler
 }
 
 // NewUpstream creates an upstream MCP server configured for the given transport.
@@ -28,7 +36,18 @@ func NewUpstream(cfg config.UpstreamConfig, logger *slog.Logger) *Upstream {
 			Name:    "easy-mcp-gateway",
 			Version: Version,
 		},
-		&mcp.ServerOptions{Logger: logger},
+		&mcp.ServerOptions{
+			Logger: logger,
+			// Advertise every capability up front: downstream servers that
+			// connect late, or gain prompts and resources on re-discovery,
+			// add items after clients have initialized.
+			Capabilities: &mcp.ServerCapabilities{
+				Logging:   &mcp.LoggingCapabilities{},
+				Tools:     &mcp.ToolCapabilities{ListChanged: true},
+				Prompts:   &mcp.PromptCapabilities{ListChanged: true},
+				Resources: &mcp.ResourceCapabilities{ListChanged: true},
+			},
+		},
 	)
 	return &Upstream{
 		Server: srv,
@@ -37,6 +56,22 @@ func NewUpstream(cfg config.UpstreamConfig, logger *slog.Logger) *Upstream {
 	}
 }
 
+// Handle serves handler at pattern on the HTTP listener, alongside the MCP
+// endpoint. Must be called before Run; ignored on the stdio transport.
+func (u *Upstream) Handle(pattern string, handler http.Handler) {
+	if u.routes == nil {
+		u.routes = make(map[string]http.Handler)
+	}
+	u.routes[pattern] = handler
+}
+
+// Use wraps the MCP endpoint of the HTTP transport in mw, e.g. to require
+// authentication. Routes added with Handle are not wrapped. Must be called
+// before Run; ignored on the stdio transport.
+func (u *Upstream) Use(mw func(http.Handler) http.Handler) {
+	u.middleware = append(u.middleware, mw)
+}
+
 // Run starts the upstream server on the configured transport and blocks
 // until ctx is cancelled or the transport closes.
 func (u *Upstream) Run(ctx context.Context) error {
@@ -56,21 +91,49 @@ func (u *Upstream) runStdio(ctx context.Context) error {
 }
 
 func (u *Upstream) runHTTP(ctx context.Context) error {
-	handler := mcp.NewStreamableHTTPHandler(
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(
 		func(_ *http.Request) *mcp.Server { return u.Server },
 		&mcp.StreamableHTTPOptions{Logger: u.logger},
 	)
+	for _, mw := range u.middleware {
+		handler = mw(handler)
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle(u.cfg.HTTP.Path, handler)
+	for pattern, h := range u.routes {
+		mux.Handle(pattern, h)
+	}
+
+	var tlsCfg *tls.Config
+	if t := u.cfg.HTTP.TLS; t != nil {
+		reloader, err := newTLSReloader(*t, u.logger)
+		if err != nil {
+			return err
+		}
+		tlsCfg = reloader.Config()
+	}
 
 	ln, err := net.Listen("tcp", u.cfg.HTTP.Addr)
 	if err != nil {
 		return fmt.Errorf("listen %s: %w", u.cfg.HTTP.Addr, err)
 	}
-	u.logger.Info("starting HTTP transport", "addr", ln.Addr(), "path", u.cfg.HTTP.Path)
+	if tlsCfg != nil {
+		ln = tls.NewListener(ln, tlsCfg)
+	}
+	u.logger.Info("starting HTTP transport", "addr", ln.Addr(), "path", u.cfg.HTTP.Path, "tls", tlsCfg != nil)
+
+	err = Serve(ctx, ln, mux)
+	if ctx.Err() != nil {
+		u.logger.Info("shut down HTTP transport")
+	}
+	return err
+}
 
-	srv := &http.Server{Handler: mux}
+// Serve serves HTTP requests on ln until ctx is cancelled, then shuts the
+// server down gracefully.
+func Serve(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	srv := &http.Server{Handler: handler}
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -79,7 +142,6 @@ func (u *Upstream) runHTTP(ctx context.Context) error {
 
 	select {
 	case <-ctx.Done():
-		u.logger.Info("shutting down HTTP transport")
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		return srv.Shutdown(shutdownCtx)
@@ -15,6 +15,33 @@ func TestNewUpstream_createsServer(t *testing.T) {
 	}
 }
 
+func TestNewUpstream_advertisesCapabilitiesWithoutItems(t *testing.T) {
+	u := NewUpstream(config.UpstreamConfig{Transport: config.TransportStdio}, testLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srvTransport, clientTransport := mcp.NewInMemoryTransports()
+	go func() {
+		_ = u.Server.Run(ctx, srvTransport)
+	}()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	caps := session.InitializeResult().Capabilities
+	if caps.Tools == nil || caps.Prompts == nil || caps.Resources == nil {
+		t.Fatalf("capabilities = %+v, want tools, prompts and resources", caps)
+	}
+	if !caps.Tools.ListChanged || !caps.Prompts.ListChanged || !caps.Resources.ListChanged {
+		t.Errorf("capabilities = %+v, want list changed notifications", caps)
+	}
+}
+
 func TestUpstream_runUnsupported(t *testing.T) {
 	u := NewUpstream(config.UpstreamConfig{Transport: "grpc"}, testLogger())
 	ctx, cancel := context.WithCancel(context.Background())